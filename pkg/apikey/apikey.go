@@ -0,0 +1,225 @@
+package apikey
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nhstop/go-utils/pkg/encryption"
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// Key is a stored API key. The secret itself is never stored, only its HMAC.
+type Key struct {
+	ID         string // public part of the key, safe to log and show in UIs
+	Name       string
+	Subject    string // owner, exposed to handlers as the authenticated subject
+	Scopes     []string
+	ExpiresAt  *time.Time // nil never expires
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// HasScopes reports whether the key was granted every scope
+func (k *Key) HasScopes(scopes ...string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(k.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Store persists keys by ID alongside the HMAC of the full key
+type Store interface {
+	Create(ctx context.Context, key *Key, hash string) error
+	// Get returns ErrNotFound for unknown IDs
+	Get(ctx context.Context, id string) (*Key, string, error)
+	Revoke(ctx context.Context, id string) error
+	// TouchLastUsed records when each key ID was last used
+	TouchLastUsed(ctx context.Context, used map[string]time.Time) error
+}
+
+var (
+	ErrNotFound = errors.New("apikey: key not found")
+	ErrInvalid  = errors.New("apikey: invalid API key")
+	ErrExpired  = errors.New("apikey: API key has expired")
+	ErrRevoked  = errors.New("apikey: API key has been revoked")
+)
+
+// idLength is the length of the encoded ID that follows the prefix
+const idLength = 16
+
+// lowercase base32 without padding, so keys are URL and header safe
+var keyEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Config configures a Manager
+type Config struct {
+	Secret        string        // HMAC secret for key hashes, required
+	Prefix        string        // identifies the key type, e.g. "sk_live", defaults to "sk"
+	FlushInterval time.Duration // how often last-used times are written, defaults to 30s
+	BufferSize    int           // pending last-used updates before new ones are dropped, defaults to 1024
+}
+
+// Manager creates and authenticates API keys
+type Manager struct {
+	store   Store
+	cfg     *Config
+	touches chan touch
+}
+
+type touch struct {
+	id string
+	at time.Time
+}
+
+// NewManager creates a Manager. Run must be started for last-used times to be recorded.
+func NewManager(store Store, cfg *Config) *Manager {
+	if cfg == nil || cfg.Secret == "" {
+		panic("apikey: Config.Secret is required")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "sk"
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 30 * time.Second
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+	return &Manager{
+		store:   store,
+		cfg:     cfg,
+		touches: make(chan touch, cfg.BufferSize),
+	}
+}
+
+// Create generates a key and stores its hash. The returned plaintext key,
+// formatted as "<prefix>_<id><secret>", is only available now.
+func (m *Manager) Create(ctx context.Context, name, subject string, scopes []string, ttl time.Duration) (string, *Key, error) {
+	id, err := randomString(10)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(20)
+	if err != nil {
+		return "", nil, err
+	}
+	plaintext := m.cfg.Prefix + "_" + id + secret
+
+	key := &Key{
+		ID:        id,
+		Name:      name,
+		Subject:   subject,
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expires := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expires
+	}
+
+	if err := m.store.Create(ctx, key, encryption.HashText(plaintext, m.cfg.Secret)); err != nil {
+		return "", nil, err
+	}
+	return plaintext, key, nil
+}
+
+// HasKeyPrefix reports whether token carries this manager's key prefix, to
+// tell API keys apart from other bearer tokens. It does not authenticate it.
+func (m *Manager) HasKeyPrefix(token string) bool {
+	return strings.HasPrefix(token, m.cfg.Prefix+"_")
+}
+
+// Authenticate verifies a plaintext key, comparing hashes in constant time
+func (m *Manager) Authenticate(ctx context.Context, plaintext string) (*Key, error) {
+	rest, ok := strings.CutPrefix(plaintext, m.cfg.Prefix+"_")
+	if !ok || len(rest) <= idLength {
+		return nil, ErrInvalid
+	}
+	hash := encryption.HashText(plaintext, m.cfg.Secret)
+
+	key, stored, err := m.store.Get(ctx, rest[:idLength])
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(hash), []byte(stored)) {
+		return nil, ErrInvalid
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, ErrRevoked
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, ErrExpired
+	}
+
+	// Never block a request on bookkeeping, drop the update when the buffer is full
+	select {
+	case m.touches <- touch{id: key.ID, at: now}:
+	default:
+	}
+	return key, nil
+}
+
+// Revoke disables a key immediately
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	return m.store.Revoke(ctx, id)
+}
+
+// Run batches last-used updates and writes them every FlushInterval until ctx
+// is cancelled, then writes what is pending. It can run as a server.Worker.
+func (m *Manager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[string]time.Time)
+	record := func(t touch) {
+		if t.at.After(pending[t.id]) {
+			pending[t.id] = t.at
+		}
+	}
+	flush := func(ctx context.Context) {
+		if len(pending) == 0 {
+			return
+		}
+		if err := m.store.TouchLastUsed(ctx, pending); err != nil {
+			logger.Error("failed to record API key usage: %v", err)
+		}
+		pending = make(map[string]time.Time)
+	}
+
+	for {
+		select {
+		case t := <-m.touches:
+			record(t)
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			// Run is the only reader, so whatever is queued can be drained
+			for len(m.touches) > 0 {
+				record(<-m.touches)
+			}
+			flush(context.WithoutCancel(ctx))
+			return nil
+		}
+	}
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyEncoding.EncodeToString(b), nil
+}
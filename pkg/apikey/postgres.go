@@ -0,0 +1,126 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps API keys in a Postgres table
+type PostgresStore struct {
+	pool  *pgxpool.Pool
+	table string
+}
+
+// NewPostgresStore creates a PostgresStore using table (defaults to "api_keys")
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	if table == "" {
+		table = "api_keys"
+	}
+	return &PostgresStore{
+		pool:  pool,
+		table: pgx.Identifier{table}.Sanitize(),
+	}
+}
+
+// CreateTable creates the keys table if it does not exist
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id           TEXT PRIMARY KEY,
+			hash         TEXT NOT NULL,
+			name         TEXT NOT NULL,
+			subject      TEXT NOT NULL,
+			scopes       TEXT[] NOT NULL DEFAULT '{}',
+			expires_at   TIMESTAMPTZ,
+			created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+			last_used_at TIMESTAMPTZ,
+			revoked_at   TIMESTAMPTZ
+		)`, s.table))
+	return err
+}
+
+// Create stores a new key
+func (s *PostgresStore) Create(ctx context.Context, key *Key, hash string) error {
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s (id, hash, name, subject, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`, s.table),
+		key.ID, hash, key.Name, key.Subject, scopes, key.ExpiresAt, key.CreatedAt)
+	return err
+}
+
+// Get returns the key with id and its hash
+func (s *PostgresStore) Get(ctx context.Context, id string) (*Key, string, error) {
+	key := &Key{ID: id}
+	var hash string
+	err := s.pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT hash, name, subject, scopes, expires_at, created_at, last_used_at, revoked_at
+		FROM %s WHERE id = $1`, s.table), id).
+		Scan(&hash, &key.Name, &key.Subject, &key.Scopes, &key.ExpiresAt, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return key, hash, nil
+}
+
+// List returns the keys owned by subject, newest first
+func (s *PostgresStore) List(ctx context.Context, subject string) ([]*Key, error) {
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, name, subject, scopes, expires_at, created_at, last_used_at, revoked_at
+		FROM %s WHERE subject = $1 ORDER BY created_at DESC`, s.table), subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*Key
+	for rows.Next() {
+		key := &Key{}
+		if err := rows.Scan(&key.ID, &key.Name, &key.Subject, &key.Scopes, &key.ExpiresAt,
+			&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke marks a key revoked, keeping the row for auditing
+func (s *PostgresStore) Revoke(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		UPDATE %s SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1`, s.table), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed updates last_used_at for a batch of keys in one statement
+func (s *PostgresStore) TouchLastUsed(ctx context.Context, used map[string]time.Time) error {
+	ids := make([]string, 0, len(used))
+	times := make([]time.Time, 0, len(used))
+	for id, t := range used {
+		ids = append(ids, id)
+		times = append(times, t)
+	}
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		UPDATE %s k SET last_used_at = u.used_at
+		FROM unnest($1::text[], $2::timestamptz[]) AS u(id, used_at)
+		WHERE k.id = u.id AND (k.last_used_at IS NULL OR k.last_used_at < u.used_at)`, s.table),
+		ids, times)
+	return err
+}
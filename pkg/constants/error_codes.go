@@ -5,18 +5,42 @@ const (
 	Empty = 0
 
 	// Generic
-	InternalServer = 1000
-	InvalidRequest = 1001
-	Unauthorized   = 1002
-	Forbidden      = 1003
+	InternalServer  = 1000
+	InvalidRequest  = 1001
+	Unauthorized    = 1002
+	Forbidden       = 1003
+	TooManyRequests = 1004
+	RequestTimeout  = 1005
+
+	// Request body
+	RequestTooLarge      = 1006
+	UnsupportedMediaType = 1007
+	RequestTooComplex    = 1008
+
+	// Conditional requests
+	PreconditionFailed   = 1009
+	PreconditionRequired = 1010
+
+	// Availability
+	MaintenanceMode = 1011
+	FeatureDisabled = 1012
+
+	// Versioning
+	UnsupportedAPIVersion = 1013
+
+	// Idempotency
+	IdempotencyKeyInUse    = 1100
+	IdempotencyKeyMismatch = 1101
 
 	// Crypto / Security
-	FailedToEncrypt   = 2000
-	FailedToDecrypt   = 2001
-	HashingFailed     = 2002
-	TokenGeneration   = 2003
-	TokenValidation   = 2004
-	FailedToGetAESKey = 2005
+	FailedToEncrypt         = 2000
+	FailedToDecrypt         = 2001
+	HashingFailed           = 2002
+	TokenGeneration         = 2003
+	TokenValidation         = 2004
+	FailedToGetAESKey       = 2005
+	CSRFTokenInvalid        = 2006
+	WebhookSignatureInvalid = 2007
 
 	// Database
 	DBError               = 3000
@@ -20,6 +20,9 @@ func Connect(databaseURL string) *pgxpool.Pool {
 	poolConfig.MaxConnLifetime = 30 * time.Minute
 	poolConfig.MaxConnIdleTime = 5 * time.Minute
 
+	// Trace queries with OpenTelemetry (no-op until a tracer provider is registered)
+	poolConfig.ConnConfig.Tracer = NewQueryTracer(nil)
+
 	// Create connection pool
 	dbpool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
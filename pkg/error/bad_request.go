@@ -23,7 +23,18 @@ func BadRequest(err error) *CodedError {
 		})
 	}
 
-	// Case 2: Validation errors
+	// Case 2: Body exceeded http.MaxBytesReader (e.g. the BodyLimit middleware)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return NewError(ErrorParams{
+			HTTPCode: http.StatusRequestEntityTooLarge,
+			Code:     constants.RequestTooLarge,
+			Message:  fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit),
+			Err:      err,
+		})
+	}
+
+	// Case 3: Validation errors
 	var validationErrs validator.ValidationErrors
 	if errors.As(err, &validationErrs) {
 		errorsMap := mapValidationErrors(validationErrs)
@@ -35,7 +46,7 @@ func BadRequest(err error) *CodedError {
 		})
 	}
 
-	// Case 3: Other JSON/binding errors
+	// Case 4: Other JSON/binding errors
 	return NewError(ErrorParams{
 		HTTPCode: http.StatusBadRequest,
 		Code:     constants.InvalidRequest,
@@ -25,6 +25,11 @@ func (c *CodedError) Error() string {
 	return fmt.Sprintf("%s (code: %d, http: %d)", c.Message, c.Code, c.HTTPCode)
 }
 
+// Unwrap exposes the underlying error to errors.Is / errors.As
+func (c *CodedError) Unwrap() error {
+	return c.Err
+}
+
 // Optional parameters struct for NewError
 type ErrorParams struct {
 	HTTPCode int
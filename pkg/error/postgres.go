@@ -1,6 +1,7 @@
 package apperr
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"net/http"
@@ -21,6 +22,16 @@ func PostgresError(err error) *CodedError {
 		})
 	}
 
+	// Handle statement timeouts and cancelled/expired contexts
+	if pgconn.Timeout(err) || errors.Is(err, context.DeadlineExceeded) {
+		return NewError(ErrorParams{
+			HTTPCode: http.StatusGatewayTimeout,
+			Code:     constants.DBTimeout,
+			Message:  "Database operation timed out",
+			Err:      err,
+		})
+	}
+
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
 		switch pgErr.Code {
@@ -52,6 +63,13 @@ func PostgresError(err error) *CodedError {
 				Message:  "Check constraint failed",
 				Err:      err,
 			})
+		case "57014": // query_canceled (statement_timeout)
+			return NewError(ErrorParams{
+				HTTPCode: http.StatusGatewayTimeout,
+				Code:     constants.DBTimeout,
+				Message:  "Database operation timed out",
+				Err:      err,
+			})
 		default: // all other Postgres errors
 			return NewError(ErrorParams{
 				HTTPCode: http.StatusInternalServerError,
@@ -0,0 +1,172 @@
+package flags
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// Flag is the state of a named flag
+type Flag struct {
+	Name    string
+	Enabled bool // on for everyone
+	// Percentage turns a disabled flag on for that share (0-100) of subjects,
+	// for gradual rollouts. Each subject consistently lands in or out.
+	Percentage int
+}
+
+// Source looks up flags. ok is false for flags the source doesn't know.
+type Source interface {
+	Flag(ctx context.Context, name string) (flag Flag, ok bool)
+}
+
+// Enabled reports whether name is on for subject. Unknown flags are off, and
+// percentage rollouts need a subject.
+func Enabled(ctx context.Context, src Source, name, subject string) bool {
+	f, ok := src.Flag(ctx, name)
+	if !ok {
+		return false
+	}
+	if f.Enabled {
+		return true
+	}
+	if f.Percentage <= 0 || subject == "" {
+		return false
+	}
+	return bucket(name, subject) < f.Percentage
+}
+
+// bucket maps subject to 0-99, independently for each flag so the same
+// users aren't always first to get every rollout
+func bucket(name, subject string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + subject))
+	return int(h.Sum32() % 100)
+}
+
+// ---- Chain ----
+
+type chain []Source
+
+// Chain asks each source in turn, the first that knows a flag wins,
+// e.g. Chain(NewEnvSource(""), postgres) lets env vars override the database
+func Chain(sources ...Source) Source {
+	return chain(sources)
+}
+
+func (c chain) Flag(ctx context.Context, name string) (Flag, bool) {
+	for _, s := range c {
+		if f, ok := s.Flag(ctx, name); ok {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// ---- Memory ----
+
+// MemorySource holds flags in memory, toggled at runtime by the application
+type MemorySource struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewMemorySource creates a MemorySource with initial flags
+func NewMemorySource(initial ...Flag) *MemorySource {
+	s := &MemorySource{flags: make(map[string]Flag)}
+	for _, f := range initial {
+		s.flags[f.Name] = f
+	}
+	return s
+}
+
+// Flag implements Source
+func (s *MemorySource) Flag(_ context.Context, name string) (Flag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.flags[name]
+	return f, ok
+}
+
+// Set adds or replaces a flag
+func (s *MemorySource) Set(f Flag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[f.Name] = f
+}
+
+// Enable turns name on for everyone
+func (s *MemorySource) Enable(name string) {
+	s.Set(Flag{Name: name, Enabled: true})
+}
+
+// Disable turns name off for everyone
+func (s *MemorySource) Disable(name string) {
+	s.Set(Flag{Name: name})
+}
+
+// ---- Environment ----
+
+// EnvSource reads flags from environment variables on every lookup. The flag
+// "new-checkout" is read from FLAG_NEW_CHECKOUT with the default prefix.
+// Values are booleans ("true", "1", "off", ...) or a rollout percentage from
+// "0%" to "100%". Invalid values are logged and the flag treated as unknown.
+type EnvSource struct {
+	prefix string
+}
+
+// NewEnvSource creates an EnvSource, prefix defaults to "FLAG_"
+func NewEnvSource(prefix string) *EnvSource {
+	if prefix == "" {
+		prefix = "FLAG_"
+	}
+	return &EnvSource{prefix: prefix}
+}
+
+// Flag implements Source
+func (s *EnvSource) Flag(_ context.Context, name string) (Flag, bool) {
+	value, ok := os.LookupEnv(s.prefix + envName(name))
+	if !ok {
+		return Flag{}, false
+	}
+	value = strings.TrimSpace(value)
+
+	if pct, isPct := strings.CutSuffix(value, "%"); isPct {
+		n, err := strconv.Atoi(strings.TrimSpace(pct))
+		if err != nil || n < 0 || n > 100 {
+			logger.Warn("ignoring %s%s=%q, percentages must be between 0%% and 100%%", s.prefix, envName(name), value)
+			return Flag{}, false
+		}
+		return Flag{Name: name, Enabled: n == 100, Percentage: n}, true
+	}
+	switch strings.ToLower(value) {
+	case "on", "yes":
+		return Flag{Name: name, Enabled: true}, true
+	case "off", "no":
+		return Flag{Name: name}, true
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Warn("ignoring %s%s=%q, expected a boolean or a percentage", s.prefix, envName(name), value)
+		return Flag{}, false
+	}
+	return Flag{Name: name, Enabled: enabled}, true
+}
+
+// envName upper-cases name and replaces anything but letters and digits with "_"
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
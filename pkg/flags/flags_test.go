@@ -0,0 +1,118 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestBucketIsStablePerFlag(t *testing.T) {
+	for i := range 100 {
+		subject := fmt.Sprintf("user-%d", i)
+		b := bucket("new-checkout", subject)
+		if b < 0 || b > 99 {
+			t.Fatalf("bucket = %d, want 0-99", b)
+		}
+		if again := bucket("new-checkout", subject); again != b {
+			t.Fatalf("bucket changed between calls: %d then %d", b, again)
+		}
+	}
+
+	// Different flags shouldn't put every subject in the same bucket
+	same := 0
+	for i := range 1000 {
+		subject := fmt.Sprintf("user-%d", i)
+		if bucket("new-checkout", subject) == bucket("dark-mode", subject) {
+			same++
+		}
+	}
+	if same > 50 {
+		t.Fatalf("%d of 1000 subjects share a bucket across flags", same)
+	}
+}
+
+func TestPercentageRolloutDistribution(t *testing.T) {
+	src := NewMemorySource(Flag{Name: "new-checkout", Percentage: 25})
+	ctx := context.Background()
+
+	const subjects = 10000
+	on := 0
+	for i := range subjects {
+		if Enabled(ctx, src, "new-checkout", fmt.Sprintf("user-%d", i)) {
+			on++
+		}
+	}
+	if on < subjects*22/100 || on > subjects*28/100 {
+		t.Fatalf("%d of %d subjects enabled, want about 25%%", on, subjects)
+	}
+	if Enabled(ctx, src, "new-checkout", "") {
+		t.Fatal("percentage rollout enabled without a subject")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	src := NewMemorySource(Flag{Name: "on", Enabled: true}, Flag{Name: "off"})
+	ctx := context.Background()
+
+	if !Enabled(ctx, src, "on", "") || Enabled(ctx, src, "off", "u1") || Enabled(ctx, src, "unknown", "u1") {
+		t.Fatal("unexpected flag states")
+	}
+	src.Disable("on")
+	src.Enable("off")
+	if Enabled(ctx, src, "on", "") || !Enabled(ctx, src, "off", "") {
+		t.Fatal("Enable/Disable had no effect")
+	}
+}
+
+func TestEnvSource(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Flag
+		ok    bool
+	}{
+		{"true", Flag{Name: "new-checkout", Enabled: true}, true},
+		{"1", Flag{Name: "new-checkout", Enabled: true}, true},
+		{" on ", Flag{Name: "new-checkout", Enabled: true}, true},
+		{"No", Flag{Name: "new-checkout"}, true},
+		{"false", Flag{Name: "new-checkout"}, true},
+		{"25%", Flag{Name: "new-checkout", Percentage: 25}, true},
+		{"0%", Flag{Name: "new-checkout"}, true},
+		{"100%", Flag{Name: "new-checkout", Enabled: true, Percentage: 100}, true},
+		{"101%", Flag{}, false},
+		{"-5%", Flag{}, false},
+		{"abc%", Flag{}, false},
+		{"maybe", Flag{}, false},
+	}
+	src := NewEnvSource("")
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("FLAG_NEW_CHECKOUT", tt.value)
+			got, ok := src.Flag(context.Background(), "new-checkout")
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("Flag = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := NewEnvSource("APP_").Flag(context.Background(), "billing.v2"); ok {
+		t.Fatal("unset variable reported as known")
+	}
+	t.Setenv("APP_BILLING_V2", "true")
+	if f, ok := NewEnvSource("APP_").Flag(context.Background(), "billing.v2"); !ok || !f.Enabled {
+		t.Fatalf("Flag = %+v, %v, want APP_BILLING_V2 to enable it", f, ok)
+	}
+}
+
+func TestChainFirstKnownSourceWins(t *testing.T) {
+	t.Setenv("FLAG_NEW_CHECKOUT", "off")
+	db := NewMemorySource(Flag{Name: "new-checkout", Enabled: true}, Flag{Name: "dark-mode", Enabled: true})
+	src := Chain(NewEnvSource(""), db)
+	ctx := context.Background()
+
+	if Enabled(ctx, src, "new-checkout", "") {
+		t.Fatal("environment did not override the later source")
+	}
+	if !Enabled(ctx, src, "dark-mode", "") {
+		t.Fatal("flag unknown to the environment was not looked up further")
+	}
+}
@@ -0,0 +1,114 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// PostgresSource serves flags from a snapshot of a Postgres table, refreshed
+// every interval by Run, so lookups never hit the database
+type PostgresSource struct {
+	pool     *pgxpool.Pool
+	table    string
+	interval time.Duration
+	snapshot atomic.Pointer[map[string]Flag]
+}
+
+// NewPostgresSource creates a PostgresSource using table (defaults to
+// "feature_flags") polled every interval (defaults to 30s)
+func NewPostgresSource(pool *pgxpool.Pool, table string, interval time.Duration) *PostgresSource {
+	if table == "" {
+		table = "feature_flags"
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	s := &PostgresSource{
+		pool:     pool,
+		table:    pgx.Identifier{table}.Sanitize(),
+		interval: interval,
+	}
+	s.snapshot.Store(&map[string]Flag{})
+	return s
+}
+
+// CreateTable creates the flags table if it does not exist
+func (s *PostgresSource) CreateTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			name       TEXT PRIMARY KEY,
+			enabled    BOOLEAN NOT NULL DEFAULT false,
+			percentage INTEGER NOT NULL DEFAULT 0 CHECK (percentage BETWEEN 0 AND 100),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`, s.table))
+	return err
+}
+
+// Flag implements Source
+func (s *PostgresSource) Flag(_ context.Context, name string) (Flag, bool) {
+	f, ok := (*s.snapshot.Load())[name]
+	return f, ok
+}
+
+// Refresh reloads every flag from the table
+func (s *PostgresSource) Refresh(ctx context.Context) error {
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`SELECT name, enabled, percentage FROM %s`, s.table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	flags := make(map[string]Flag)
+	for rows.Next() {
+		var f Flag
+		if err := rows.Scan(&f.Name, &f.Enabled, &f.Percentage); err != nil {
+			return err
+		}
+		flags[f.Name] = f
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.snapshot.Store(&flags)
+	return nil
+}
+
+// Set upserts a flag. Other instances see it on their next refresh.
+func (s *PostgresSource) Set(ctx context.Context, f Flag) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s (name, enabled, percentage) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			enabled = EXCLUDED.enabled, percentage = EXCLUDED.percentage, updated_at = now()`, s.table),
+		f.Name, f.Enabled, f.Percentage)
+	if err != nil {
+		return err
+	}
+	return s.Refresh(ctx)
+}
+
+// Run refreshes the snapshot every interval until ctx is cancelled, keeping
+// the last good snapshot when a refresh fails. It can run as a server.Worker.
+func (s *PostgresSource) Run(ctx context.Context) error {
+	if err := s.Refresh(ctx); err != nil {
+		logger.Error("failed to load feature flags: %v", err)
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("failed to refresh feature flags: %v", err)
+			}
+		}
+	}
+}
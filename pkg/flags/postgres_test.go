@@ -0,0 +1,73 @@
+package flags
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testSource connects to TEST_DATABASE_URL, skipping the test when it is unset
+func testSource(t *testing.T, interval time.Duration) *PostgresSource {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	src := NewPostgresSource(pool, "feature_flags_test", interval)
+	if _, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+src.table); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CreateTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+src.table) })
+	return src
+}
+
+func TestPostgresSource(t *testing.T) {
+	src := testSource(t, time.Hour)
+	ctx := context.Background()
+
+	if _, ok := src.Flag(ctx, "new-checkout"); ok {
+		t.Fatal("empty table reported a flag")
+	}
+	if err := src.Set(ctx, Flag{Name: "new-checkout", Percentage: 25}); err != nil {
+		t.Fatal(err)
+	}
+	if f, ok := src.Flag(ctx, "new-checkout"); !ok || f.Percentage != 25 {
+		t.Fatalf("Flag = %+v, %v, want the stored rollout", f, ok)
+	}
+	if err := src.Set(ctx, Flag{Name: "new-checkout", Percentage: 150}); err == nil {
+		t.Fatal("Set accepted a percentage above 100")
+	}
+}
+
+func TestPostgresSourceRunPicksUpChanges(t *testing.T) {
+	src := testSource(t, 20*time.Millisecond)
+	other := NewPostgresSource(src.pool, "feature_flags_test", time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go src.Run(ctx)
+
+	// Written by another instance, seen on src's next refresh
+	if err := other.Set(context.Background(), Flag{Name: "dark-mode", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !Enabled(context.Background(), src, "dark-mode", "") {
+		if time.Now().After(deadline) {
+			t.Fatal("Run did not refresh the snapshot")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
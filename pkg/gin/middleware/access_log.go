@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// AccessLogFormat selects how AccessLog writes entries
+type AccessLogFormat int
+
+const (
+	// AccessLogStructured writes key=value fields through the logger package
+	AccessLogStructured AccessLogFormat = iota
+	// AccessLogCombined writes Apache Combined Log Format lines to Output
+	AccessLogCombined
+	// AccessLogJSON writes one JSON object per line to Output
+	AccessLogJSON
+)
+
+// AccessLogConfig configures the AccessLog middleware
+type AccessLogConfig struct {
+	Format AccessLogFormat
+	// SkipPaths are not logged, e.g. "/healthz". A trailing "*" matches a prefix.
+	SkipPaths []string
+	// RedactQueryParams have their values replaced in the logged query string
+	RedactQueryParams []string
+	// Output receives Combined and JSON lines, defaults to os.Stdout
+	Output io.Writer
+}
+
+// DefaultAccessLogConfig logs structured fields, skips health checks and redacts common secrets
+func DefaultAccessLogConfig() *AccessLogConfig {
+	return &AccessLogConfig{
+		Format:            AccessLogStructured,
+		SkipPaths:         []string{"/healthz", "/livez", "/readyz"},
+		RedactQueryParams: []string{"token", "access_token", "refresh_token", "password", "secret", "api_key", "key", "code", "otp"},
+		Output:            os.Stdout,
+	}
+}
+
+// AccessLogEntry is everything AccessLog records about a request
+type AccessLogEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	ClientIP  string    `json:"client_ip"`
+	Subject   string    `json:"subject,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Route     string    `json:"route,omitempty"`
+	Query     string    `json:"query,omitempty"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Latency   float64   `json:"latency_ms"`
+	BytesIn   int64     `json:"bytes_in"`
+	BytesOut  int       `json:"bytes_out"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+// countingReader counts bytes handlers actually read from the request body
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// AccessLog logs one entry per request.
+// Client IPs come from ClientIP, so register RealIP when running behind a
+// load balancer.
+func AccessLog(cfg *AccessLogConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultAccessLogConfig()
+	}
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+
+	skip := newPathMatcher(cfg.SkipPaths)
+
+	redact := make(map[string]bool)
+	for _, p := range cfg.RedactQueryParams {
+		redact[strings.ToLower(p)] = true
+	}
+
+	var mu sync.Mutex // serializes writes to Output
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if skip.match(path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var body *countingReader
+		if c.Request.Body != nil {
+			body = &countingReader{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+
+		// Process request
+		c.Next()
+
+		entry := AccessLogEntry{
+			Time:      start,
+			RequestID: GetRequestID(c),
+			ClientIP:  ClientIP(c),
+			Subject:   c.GetString(SubjectKey),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.EscapedPath(),
+			Route:     c.FullPath(),
+			Query:     redactQuery(c.Request.URL.RawQuery, redact),
+			Proto:     c.Request.Proto,
+			Status:    c.Writer.Status(),
+			Latency:   float64(time.Since(start).Microseconds()) / 1000,
+			BytesOut:  max(c.Writer.Size(), 0),
+			Referer:   c.Request.Referer(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if body != nil && body.n > 0 {
+			entry.BytesIn = body.n
+		} else if c.Request.ContentLength > 0 {
+			entry.BytesIn = c.Request.ContentLength
+		}
+
+		switch cfg.Format {
+		case AccessLogCombined:
+			mu.Lock()
+			fmt.Fprintln(cfg.Output, formatCombined(&entry))
+			mu.Unlock()
+		case AccessLogJSON:
+			line, err := json.Marshal(&entry)
+			if err != nil {
+				logger.Error("failed to encode access log entry: %v", err)
+				return
+			}
+			mu.Lock()
+			cfg.Output.Write(append(line, '\n'))
+			mu.Unlock()
+		default:
+			logger.Info("%s", formatStructured(&entry))
+		}
+	}
+}
+
+// redactQuery replaces the values of sensitive query parameters
+func redactQuery(rawQuery string, redact map[string]bool) string {
+	if rawQuery == "" || len(redact) == 0 {
+		return rawQuery
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// Unparseable queries are dropped rather than risk leaking secrets
+		return "[unparseable]"
+	}
+
+	changed := false
+	for name, vals := range values {
+		if redact[strings.ToLower(name)] {
+			for i := range vals {
+				vals[i] = "REDACTED"
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+func formatStructured(e *AccessLogEntry) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "method=%s path=%q", e.Method, e.Path)
+	if e.Route != "" {
+		fmt.Fprintf(&sb, " route=%q", e.Route)
+	}
+	if e.Query != "" {
+		fmt.Fprintf(&sb, " query=%q", e.Query)
+	}
+	fmt.Fprintf(&sb, " status=%d latency_ms=%.3f client_ip=%s bytes_in=%d bytes_out=%d",
+		e.Status, e.Latency, e.ClientIP, e.BytesIn, e.BytesOut)
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " request_id=%s", e.RequestID)
+	}
+	if e.Subject != "" {
+		fmt.Fprintf(&sb, " subject=%q", e.Subject)
+	}
+	if e.UserAgent != "" {
+		fmt.Fprintf(&sb, " user_agent=%q", e.UserAgent)
+	}
+	return sb.String()
+}
+
+// formatCombined renders Apache Combined Log Format:
+// %h %l %u [%t] "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+func formatCombined(e *AccessLogEntry) string {
+	uri := e.Path
+	if e.Query != "" {
+		uri += "?" + e.Query
+	}
+	bytesOut := "-"
+	if e.BytesOut > 0 {
+		bytesOut = fmt.Sprint(e.BytesOut)
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s "%s" "%s"`,
+		escapeLogField(e.ClientIP),
+		dashIfEmpty(escapeLogField(e.Subject)),
+		e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		escapeLogField(e.Method), escapeLogField(uri), escapeLogField(e.Proto),
+		e.Status, bytesOut,
+		dashIfEmpty(escapeLogField(e.Referer)),
+		dashIfEmpty(escapeLogField(e.UserAgent)),
+	)
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// escapeLogField escapes quotes, backslashes and control characters the way
+// Apache does, so client-supplied values can't break out of a field or forge
+// log lines
+func escapeLogField(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case b == '"' || b == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case b < 0x20 || b == 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	return sb.String()
+}
+
+// pathMatcher matches exact paths, or prefixes for patterns ending in "*"
+type pathMatcher struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+func newPathMatcher(patterns []string) *pathMatcher {
+	m := &pathMatcher{exact: make(map[string]bool)}
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			m.prefixes = append(m.prefixes, prefix)
+		} else {
+			m.exact[p] = true
+		}
+	}
+	return m
+}
+
+func (m *pathMatcher) match(path string) bool {
+	if m.exact[path] {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
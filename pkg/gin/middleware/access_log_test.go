@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLogCombinedEscapesInjectedLines(t *testing.T) {
+	var out bytes.Buffer
+	r := newTestRouter(func(c *gin.Context) {
+		c.Set(SubjectKey, "bob\nmallory")
+		c.Next()
+	}, AccessLog(&AccessLogConfig{Format: AccessLogCombined, Output: &out}))
+	r.NoRoute(ok)
+
+	req := httptest.NewRequest(http.MethodGet,
+		`/x%0A6.6.6.6%20-%20admin%20[01/Jan/2026:00:00:00%20+0000]%20%22GET%20/fake?q=%22\`, nil)
+	req.Header.Set("User-Agent", "agent\r\n\"quoted\\")
+	serve(r, req)
+
+	line := strings.TrimSuffix(out.String(), "\n")
+	if strings.ContainsAny(line, "\r\n") {
+		t.Fatalf("log entry spans several lines: %q", out.String())
+	}
+	if !strings.Contains(line, `/x%0A6.6.6.6`) {
+		t.Fatalf("path not logged escaped: %s", line)
+	}
+	if !strings.Contains(line, `bob\x0amallory`) {
+		t.Fatalf("subject not escaped: %s", line)
+	}
+	if !strings.Contains(line, `"agent\x0d\x0a\"quoted\\"`) {
+		t.Fatalf("user agent not escaped: %s", line)
+	}
+}
+
+func TestAccessLogSkipPaths(t *testing.T) {
+	var out bytes.Buffer
+	r := newTestRouter(AccessLog(&AccessLogConfig{Format: AccessLogJSON, Output: &out, SkipPaths: []string{"/health*"}}))
+	r.NoRoute(ok)
+
+	serve(r, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if out.Len() != 0 {
+		t.Fatalf("skipped path was logged: %s", out.String())
+	}
+	serve(r, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if !strings.Contains(out.String(), `"/orders"`) {
+		t.Fatalf("missing entry: %s", out.String())
+	}
+}
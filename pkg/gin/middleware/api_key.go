@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/apikey"
+	"github.com/nhstop/go-utils/pkg/constants"
+)
+
+// APIKeyConfig configures the APIKey middleware
+type APIKeyConfig struct {
+	Manager *apikey.Manager
+	// Header defaults to "X-API-Key". "Authorization: Bearer <key>" is also
+	// accepted when the token has the manager's key prefix, other bearer
+	// tokens such as JWTs are left for the next auth middleware.
+	Header string
+	// Optional lets requests without a key through, e.g. when JWT auth runs next
+	Optional bool
+}
+
+// APIKey authenticates machine clients by API key. The key's owner is stored
+// under SubjectKey and the key itself under APIKeyKey, see GetAPIKey.
+func APIKey(cfg *APIKeyConfig) gin.HandlerFunc {
+	if cfg == nil || cfg.Manager == nil {
+		panic("middleware: APIKeyConfig.Manager is required")
+	}
+	if cfg.Header == "" {
+		cfg.Header = "X-API-Key"
+	}
+
+	return func(c *gin.Context) {
+		plaintext := c.GetHeader(cfg.Header)
+		if plaintext == "" {
+			if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && cfg.Manager.HasKeyPrefix(token) {
+				plaintext = token
+			}
+		}
+		if plaintext == "" {
+			if cfg.Optional {
+				c.Next()
+				return
+			}
+			abortWithError(c, http.StatusUnauthorized, constants.Unauthorized, "API key is required", nil)
+			return
+		}
+
+		key, err := cfg.Manager.Authenticate(c.Request.Context(), plaintext)
+		switch {
+		case err == nil:
+		case errors.Is(err, apikey.ErrExpired):
+			abortWithError(c, http.StatusUnauthorized, constants.Unauthorized, "API key has expired", nil)
+			return
+		case errors.Is(err, apikey.ErrRevoked):
+			abortWithError(c, http.StatusUnauthorized, constants.Unauthorized, "API key has been revoked", nil)
+			return
+		case errors.Is(err, apikey.ErrInvalid):
+			abortWithError(c, http.StatusUnauthorized, constants.Unauthorized, "invalid API key", nil)
+			return
+		default:
+			abortWithError(c, http.StatusInternalServerError, constants.InternalServer, "failed to verify API key", err)
+			return
+		}
+
+		c.Set(SubjectKey, key.Subject)
+		c.Set(APIKeyKey, key)
+		c.Next()
+	}
+}
+
+// GetAPIKey returns the key authenticated by APIKey, or nil
+func GetAPIKey(c *gin.Context) *apikey.Key {
+	key, _ := c.Get(APIKeyKey)
+	k, _ := key.(*apikey.Key)
+	return k
+}
+
+// RequireScopes rejects requests whose API key lacks any of scopes with 403.
+// Register it after APIKey.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := GetAPIKey(c)
+		if key == nil {
+			abortWithError(c, http.StatusUnauthorized, constants.Unauthorized, "API key is required", nil)
+			return
+		}
+		if !key.HasScopes(scopes...) {
+			abortWithError(c, http.StatusForbidden, constants.Forbidden,
+				"API key requires scopes: "+strings.Join(scopes, ", "), nil)
+			return
+		}
+		c.Next()
+	}
+}
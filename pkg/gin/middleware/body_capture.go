@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyCaptureConfig configures the BodyCapture middleware
+type BodyCaptureConfig struct {
+	MaxBodySize  int      // bytes kept per body, default 4 KB
+	ContentTypes []string // captured content type prefixes
+	MinStatus    int      // lowest status to attach bodies for, default 400
+	MaxStatus    int      // highest status to attach bodies for, default 599
+	RedactFields []string // JSON / form fields whose values are replaced, case-insensitive
+}
+
+// DefaultBodyCaptureConfig captures JSON and form bodies of failed requests
+func DefaultBodyCaptureConfig() *BodyCaptureConfig {
+	return &BodyCaptureConfig{
+		MaxBodySize:  4 << 10,
+		ContentTypes: []string{"application/json", "application/x-www-form-urlencoded", "text/plain"},
+		MinStatus:    400,
+		MaxStatus:    599,
+		RedactFields: []string{"password", "new_password", "old_password", "token", "access_token", "refresh_token", "secret", "otp", "api_key", "authorization", "card_number", "cvv"},
+	}
+}
+
+// CapturedBodies holds the (redacted, possibly truncated) request and response bodies
+type CapturedBodies struct {
+	Request           string
+	RequestTruncated  bool
+	Response          string
+	ResponseTruncated bool
+}
+
+// cappedBuffer keeps the first max bytes written to it
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureWriter copies response bytes into a cappedBuffer
+type captureWriter struct {
+	gin.ResponseWriter
+	capture *cappedBuffer
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.capture.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// bodyCapture is stored on the context while the request runs
+type bodyCapture struct {
+	cfg         *BodyCaptureConfig
+	redact      map[string]bool
+	redactRegex *regexp.Regexp
+	reqType     string
+	req         *cappedBuffer
+	resp        *cappedBuffer
+	respHeader  func() string
+}
+
+// BodyCapture records request and response bodies so ErrorHandler can attach
+// them to its log entry. Bodies are only kept for configured content types and
+// only logged when the final status is within [MinStatus, MaxStatus].
+// Register it after ErrorHandler:
+//
+//	r.Use(middleware.ErrorHandler(), middleware.BodyCapture(nil))
+func BodyCapture(cfg *BodyCaptureConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultBodyCaptureConfig()
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 4 << 10
+	}
+	if cfg.MinStatus == 0 && cfg.MaxStatus == 0 {
+		cfg.MinStatus, cfg.MaxStatus = 400, 599
+	}
+
+	redact := make(map[string]bool)
+	quoted := make([]string, 0, len(cfg.RedactFields))
+	for _, f := range cfg.RedactFields {
+		redact[strings.ToLower(f)] = true
+		quoted = append(quoted, regexp.QuoteMeta(f))
+	}
+
+	// Used for truncated JSON that can no longer be parsed
+	var redactRegex *regexp.Regexp
+	if len(quoted) > 0 {
+		redactRegex = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]]*)`)
+	}
+
+	return func(c *gin.Context) {
+		capture := &bodyCapture{
+			cfg:         cfg,
+			redact:      redact,
+			redactRegex: redactRegex,
+			reqType:     c.ContentType(),
+			resp:        &cappedBuffer{max: cfg.MaxBodySize},
+		}
+
+		if c.Request.Body != nil && capturableType(cfg.ContentTypes, capture.reqType) {
+			capture.req = &cappedBuffer{max: cfg.MaxBodySize}
+			c.Request.Body = teeReadCloser{
+				Reader: io.TeeReader(c.Request.Body, capture.req),
+				Closer: c.Request.Body,
+			}
+		}
+
+		writer := c.Writer
+		capture.respHeader = func() string { return writer.Header().Get("Content-Type") }
+		c.Writer = &captureWriter{ResponseWriter: writer, capture: capture.resp}
+		c.Set(capturedBodiesKey, capture)
+
+		c.Next()
+
+		c.Writer = writer
+	}
+}
+
+// GetCapturedBodies returns bodies captured by BodyCapture if status is in the configured range
+func GetCapturedBodies(c *gin.Context, status int) (*CapturedBodies, bool) {
+	v, ok := c.Get(capturedBodiesKey)
+	if !ok {
+		return nil, false
+	}
+	capture := v.(*bodyCapture)
+	if status < capture.cfg.MinStatus || status > capture.cfg.MaxStatus {
+		return nil, false
+	}
+
+	bodies := &CapturedBodies{}
+	if capture.req != nil {
+		bodies.Request = capture.redactBody(capture.reqType, capture.req)
+		bodies.RequestTruncated = capture.req.truncated
+	}
+	respType := capture.respHeader()
+	if capture.resp.buf.Len() > 0 && capturableType(capture.cfg.ContentTypes, respType) {
+		bodies.Response = capture.redactBody(respType, capture.resp)
+		bodies.ResponseTruncated = capture.resp.truncated
+	}
+	if bodies.Request == "" && bodies.Response == "" {
+		return nil, false
+	}
+	return bodies, true
+}
+
+func capturableType(allowed []string, contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, t := range allowed {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bodyCapture) redactBody(contentType string, body *cappedBuffer) string {
+	raw := body.buf.Bytes()
+	if len(raw) == 0 || len(b.redact) == 0 {
+		return string(raw)
+	}
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		if !body.truncated {
+			var v any
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			if err := dec.Decode(&v); err == nil {
+				if out, err := json.Marshal(b.redactJSON(v)); err == nil {
+					return string(out)
+				}
+			}
+		}
+		return b.redactRegex.ReplaceAllString(string(raw), `$1"REDACTED"`)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		return redactQuery(string(raw), b.redact)
+	default:
+		return string(raw)
+	}
+}
+
+func (b *bodyCapture) redactJSON(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, inner := range val {
+			if b.redact[strings.ToLower(k)] {
+				val[k] = "REDACTED"
+			} else {
+				val[k] = b.redactJSON(inner)
+			}
+		}
+	case []any:
+		for i := range val {
+			val[i] = b.redactJSON(val[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// captureRouter runs BodyCapture and returns what GetCapturedBodies reports
+// once the handler has responded with status
+func captureRouter(cfg *BodyCaptureConfig, status int, response string, got **CapturedBodies) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		*got, _ = GetCapturedBodies(c, c.Writer.Status())
+	}, BodyCapture(cfg))
+	r.POST("/", func(c *gin.Context) {
+		io.ReadAll(c.Request.Body)
+		c.Data(status, "application/json", []byte(response))
+	})
+	return r
+}
+
+func captureRequest(contentType, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestBodyCaptureRedactsJSON(t *testing.T) {
+	var got *CapturedBodies
+	r := captureRouter(nil, http.StatusBadRequest, `{"error":"bad","access_token":"tok"}`, &got)
+
+	serve(r, captureRequest("application/json", `{"email":"a@example.com","Password":"hunter2","nested":[{"otp":"123456"}]}`))
+	if got == nil {
+		t.Fatal("no bodies captured for a 400")
+	}
+	for _, secret := range []string{"hunter2", "123456", "tok\""} {
+		if strings.Contains(got.Request+got.Response, secret) {
+			t.Fatalf("captured bodies leak %q: %+v", secret, got)
+		}
+	}
+	if !strings.Contains(got.Request, "a@example.com") || !strings.Contains(got.Response, `"error":"bad"`) {
+		t.Fatalf("captured bodies lost unredacted fields: %+v", got)
+	}
+}
+
+func TestBodyCaptureRedactsTruncatedJSON(t *testing.T) {
+	cfg := DefaultBodyCaptureConfig()
+	cfg.MaxBodySize = 40
+	var got *CapturedBodies
+	r := captureRouter(cfg, http.StatusBadRequest, `{}`, &got)
+
+	serve(r, captureRequest("application/json", `{"password":"hunter2","padding":"`+strings.Repeat("x", 100)+`"}`))
+	if got == nil || !got.RequestTruncated {
+		t.Fatalf("captured = %+v, want a truncated request", got)
+	}
+	if strings.Contains(got.Request, "hunter2") {
+		t.Fatalf("truncated request leaks the password: %q", got.Request)
+	}
+}
+
+func TestBodyCaptureRedactsForms(t *testing.T) {
+	var got *CapturedBodies
+	r := captureRouter(nil, http.StatusUnprocessableEntity, `{}`, &got)
+
+	serve(r, captureRequest("application/x-www-form-urlencoded", "user=alice&password=hunter2"))
+	if got == nil || strings.Contains(got.Request, "hunter2") || !strings.Contains(got.Request, "alice") {
+		t.Fatalf("captured = %+v, want the password redacted", got)
+	}
+}
+
+func TestBodyCaptureSkipsSuccessAndOtherTypes(t *testing.T) {
+	var got *CapturedBodies
+	serve(captureRouter(nil, http.StatusOK, `{}`, &got), captureRequest("application/json", `{"a":1}`))
+	if got != nil {
+		t.Fatalf("captured bodies for a 200: %+v", got)
+	}
+
+	serve(captureRouter(nil, http.StatusBadRequest, ``, &got), captureRequest("application/octet-stream", "binary"))
+	if got != nil {
+		t.Fatalf("captured a body with an unlisted content type: %+v", got)
+	}
+}
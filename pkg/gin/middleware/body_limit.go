@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+)
+
+// BodyLimitConfig configures the BodyLimit middleware
+type BodyLimitConfig struct {
+	MaxBytes int64            // maximum body size, default 1 MB
+	Routes   map[string]int64 // per route template (c.FullPath()), e.g. "/uploads" -> 20 MB
+	// ContentTypes lists media types accepted for requests with a body,
+	// e.g. "application/json". Empty accepts anything.
+	ContentTypes []string
+	// JSON structure limits, checked for JSON bodies. 0 disables a limit.
+	MaxJSONDepth       int
+	MaxJSONArrayLength int
+}
+
+// DefaultBodyLimitConfig accepts JSON bodies up to 1 MB, nested at most 32 levels
+// with arrays of at most 10,000 elements
+func DefaultBodyLimitConfig() *BodyLimitConfig {
+	return &BodyLimitConfig{
+		MaxBytes:           1 << 20,
+		ContentTypes:       []string{"application/json"},
+		MaxJSONDepth:       32,
+		MaxJSONArrayLength: 10000,
+	}
+}
+
+var (
+	errJSONTooDeep     = errors.New("JSON body is nested too deeply")
+	errJSONArrayTooBig = errors.New("JSON body contains an array that is too long")
+)
+
+// BodyLimit rejects bodies larger than the configured size with 413,
+// unexpected content types with 415, and overly complex JSON with 400.
+// Bodies are wrapped in http.MaxBytesReader, so handlers binding chunked
+// bodies also stop at the limit and apperr.BadRequest reports 413.
+func BodyLimit(cfg *BodyLimitConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultBodyLimitConfig()
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 1 << 20
+	}
+
+	allowed := make(map[string]bool)
+	for _, t := range cfg.ContentTypes {
+		allowed[strings.ToLower(t)] = true
+	}
+
+	return func(c *gin.Context) {
+		if !hasBody(c.Request) {
+			c.Next()
+			return
+		}
+
+		limit := cfg.MaxBytes
+		if l, ok := cfg.Routes[c.FullPath()]; ok {
+			limit = l
+		}
+
+		if c.Request.ContentLength > limit {
+			abortWithError(c, http.StatusRequestEntityTooLarge, constants.RequestTooLarge,
+				fmt.Sprintf("request body must not be larger than %d bytes", limit), nil)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		mediaType = strings.ToLower(mediaType)
+		if len(allowed) > 0 && (err != nil || !allowed[mediaType]) {
+			abortWithError(c, http.StatusUnsupportedMediaType, constants.UnsupportedMediaType,
+				"unsupported content type, expected "+strings.Join(cfg.ContentTypes, " or "), nil)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+		isJSON := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+		if isJSON && (cfg.MaxJSONDepth > 0 || cfg.MaxJSONArrayLength > 0) {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.Error(apperr.BadRequest(err))
+				c.Abort()
+				return
+			}
+			if err := checkJSONLimits(body, cfg.MaxJSONDepth, cfg.MaxJSONArrayLength); err != nil {
+				abortWithError(c, http.StatusBadRequest, constants.RequestTooComplex, err.Error(), nil)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+	}
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && (r.ContentLength != 0 || len(r.TransferEncoding) > 0)
+}
+
+// checkJSONLimits walks the token stream without building the document.
+// Syntax errors are left for the handler's binding to report.
+func checkJSONLimits(body []byte, maxDepth, maxArrayLength int) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+
+	// For every open container, the element count so far or -1 for objects
+	var stack []int
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// Every other token is a value, or a key when inside an object
+		if n := len(stack); n > 0 && stack[n-1] >= 0 {
+			stack[n-1]++
+			if maxArrayLength > 0 && stack[n-1] > maxArrayLength {
+				return errJSONArrayTooBig
+			}
+		}
+
+		if isDelim {
+			if delim == '[' {
+				stack = append(stack, 0)
+			} else {
+				stack = append(stack, -1)
+			}
+			if maxDepth > 0 && len(stack) > maxDepth {
+				return errJSONTooDeep
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+)
+
+func jsonRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestBodyLimit(t *testing.T) {
+	cfg := DefaultBodyLimitConfig()
+	cfg.MaxBytes = 64
+	cfg.MaxJSONDepth = 3
+	r := newTestRouter(BodyLimit(cfg))
+	r.POST("/", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Error(apperr.BadRequest(err))
+			return
+		}
+		ok(c)
+	})
+
+	assertStatus(t, serve(r, jsonRequest(`{"a":1}`)), http.StatusOK)
+	assertStatus(t, serve(r, jsonRequest(`{"a":"`+strings.Repeat("x", 64)+`"}`)), http.StatusRequestEntityTooLarge)
+	assertStatus(t, serve(r, jsonRequest(`[[[[1]]]]`)), http.StatusBadRequest)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assertStatus(t, serve(r, req), http.StatusUnsupportedMediaType)
+
+	// Chunked bodies have no Content-Length and are cut off while reading
+	chunked := jsonRequest(`{"a":"` + strings.Repeat("x", 64) + `"}`)
+	chunked.ContentLength = -1
+	chunked.TransferEncoding = []string{"chunked"}
+	assertStatus(t, serve(r, chunked), http.StatusRequestEntityTooLarge)
+}
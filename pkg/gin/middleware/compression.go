@@ -0,0 +1,368 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings supported by Compression
+const (
+	EncodingBrotli  = "br"
+	EncodingZstd    = "zstd"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// CompressionConfig configures the Compression middleware
+type CompressionConfig struct {
+	// Encodings in order of preference when the client accepts several equally
+	Encodings []string
+	// Level for gzip and deflate, from gzip.HuffmanOnly to gzip.BestCompression,
+	// defaults to gzip.DefaultCompression.
+	// Brotli uses level 4 and zstd its default speed, both suited to dynamic responses.
+	Level int
+	// MinSize is the smallest body worth compressing, default 1 KB
+	MinSize int
+	// ContentTypes lists media types to compress, "text/*" matches a whole type
+	ContentTypes []string
+	SkipPaths    []string // exact paths, or prefixes ending in "*"
+}
+
+// DefaultCompressionConfig compresses JSON and text bodies of at least 1 KB
+func DefaultCompressionConfig() *CompressionConfig {
+	return &CompressionConfig{
+		Encodings: []string{EncodingBrotli, EncodingZstd, EncodingGzip, EncodingDeflate},
+		Level:     gzip.DefaultCompression,
+		MinSize:   1024,
+		ContentTypes: []string{
+			"application/json",
+			"application/problem+json",
+			"application/javascript",
+			"application/xml",
+			"image/svg+xml",
+			"text/*",
+		},
+	}
+}
+
+// encoder is implemented by every pooled compressor
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoderPools holds one pool per content coding
+type encoderPools map[string]*sync.Pool
+
+func newEncoderPools(level int) encoderPools {
+	return encoderPools{
+		EncodingGzip: {New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, level)
+			return w
+		}},
+		EncodingDeflate: {New: func() any {
+			w, _ := flate.NewWriter(io.Discard, level)
+			return w
+		}},
+		EncodingBrotli: {New: func() any {
+			return brotli.NewWriterLevel(io.Discard, 4)
+		}},
+		EncodingZstd: {New: func() any {
+			w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+			return w
+		}},
+	}
+}
+
+// Compression compresses responses with the best encoding the client accepts.
+// Bodies are held back until MinSize bytes are written or the handler returns,
+// so small responses are sent as-is. Responses that already have a
+// Content-Encoding, HEAD requests and statuses without a body are left alone.
+// Strong ETags on compressed responses are made weak, since the bytes differ.
+func Compression(cfg *CompressionConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultCompressionConfig()
+	}
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = DefaultCompressionConfig().Encodings
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = DefaultCompressionConfig().ContentTypes
+	}
+	if cfg.Level == 0 {
+		cfg.Level = gzip.DefaultCompression
+	}
+	if cfg.Level < gzip.HuffmanOnly || cfg.Level > gzip.BestCompression {
+		panic("middleware: CompressionConfig.Level must be between gzip.HuffmanOnly and gzip.BestCompression, got " + strconv.Itoa(cfg.Level))
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = 1024
+	}
+
+	pools := newEncoderPools(cfg.Level)
+	for _, e := range cfg.Encodings {
+		if pools[e] == nil {
+			panic("middleware: unsupported compression encoding " + strconv.Quote(e))
+		}
+	}
+
+	skip := newPathMatcher(cfg.SkipPaths)
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || skip.match(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.Encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		cw := &compressWriter{
+			ResponseWriter: original,
+			cfg:            cfg,
+			pool:           pools[encoding],
+			encoding:       encoding,
+		}
+		c.Writer = cw
+		done := false
+		defer func() {
+			if done {
+				cw.close()
+			} else {
+				// The handler panicked, let Recovery respond instead
+				cw.abort()
+			}
+			c.Writer = original
+		}()
+
+		c.Next()
+		done = true
+	}
+}
+
+// negotiateEncoding picks the supported encoding with the highest q-value,
+// breaking ties by the server's preference order
+func negotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+
+	q := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		if name == "*" {
+			wildcard = weight
+		} else if name != "" {
+			q[name] = weight
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, e := range supported {
+		weight, ok := q[e]
+		if !ok {
+			weight = wildcard
+		}
+		if weight > bestQ {
+			best, bestQ = e, weight
+		}
+	}
+	return best
+}
+
+// compressWriter buffers up to MinSize bytes, then decides whether to compress
+type compressWriter struct {
+	gin.ResponseWriter
+	cfg      *CompressionConfig
+	pool     *sync.Pool
+	encoding string
+
+	buf       []byte
+	decided   bool
+	enc       encoder // nil when the response is sent uncompressed
+	wroteHead bool
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	w.wroteHead = true
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.cfg.MinSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is deferred until the encoding is decided
+func (w *compressWriter) WriteHeaderNow() {
+	w.wroteHead = true
+	if w.decided {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *compressWriter) Written() bool {
+	return w.wroteHead || w.ResponseWriter.Written()
+}
+
+// Flush sends what has been buffered so far, for streaming responses
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// shouldCompress checks the response once its headers are final
+func (w *compressWriter) shouldCompress() bool {
+	status := w.ResponseWriter.Status()
+	if status == http.StatusNotModified {
+		// Match the headers of the compressed 200 the client revalidates
+		w.Header().Add("Vary", "Accept-Encoding")
+		weakenETag(w.Header())
+		return false
+	}
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusPartialContent {
+		return false
+	}
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf)
+	}
+	if !compressibleType(w.cfg.ContentTypes, contentType) {
+		return false
+	}
+
+	h.Add("Vary", "Accept-Encoding")
+	return len(w.buf) >= w.cfg.MinSize
+}
+
+// decide picks compressed or plain output and writes the buffered bytes
+func (w *compressWriter) decide() error {
+	w.decided = true
+	h := w.Header()
+
+	if w.shouldCompress() {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		weakenETag(h)
+		w.enc = w.pool.Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	if len(w.buf) == 0 {
+		if w.wroteHead {
+			w.ResponseWriter.WriteHeaderNow()
+		}
+		return nil
+	}
+
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+// close finishes the response after the handler returns
+func (w *compressWriter) close() {
+	if !w.decided {
+		if !w.wroteHead {
+			// Nothing written, leave the response to ErrorHandler or gin
+			return
+		}
+		w.decide()
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// abort drops whatever was buffered without sending it
+func (w *compressWriter) abort() {
+	w.buf = nil
+	if w.enc != nil {
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+func weakenETag(h http.Header) {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+func compressibleType(allowed []string, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range allowed {
+		t = strings.ToLower(t)
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompressionGzip(t *testing.T) {
+	body := strings.Repeat(`{"hello":"world"}`, 100)
+	r := newTestRouter(Compression(&CompressionConfig{Encodings: []string{EncodingGzip}}))
+	r.GET("/big", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.Data(http.StatusOK, "application/json", []byte(body))
+	})
+	r.GET("/small", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{}`))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/big", nil)
+	req.Header.Set("Accept-Encoding", "br;q=0, gzip")
+	w := serve(r, req)
+	assertStatus(t, w, http.StatusOK)
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("headers = %v", w.Header())
+	}
+	if w.Header().Get("ETag") != `W/"v1"` {
+		t.Fatalf("ETag = %q, want it weakened", w.Header().Get("ETag"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != body {
+		t.Fatal("decompressed body differs")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = serve(r, req)
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != `{}` {
+		t.Fatal("small response was compressed")
+	}
+}
+
+func TestCompressionRejectsInvalidLevel(t *testing.T) {
+	assertPanics(t, "level 10", func() { Compression(&CompressionConfig{Level: 10}) })
+	assertPanics(t, "level -3", func() { Compression(&CompressionConfig{Level: -3}) })
+	Compression(&CompressionConfig{Level: gzip.BestCompression})
+}
+
+func TestCompressionLetsRecoveryRespondToPanics(t *testing.T) {
+	r := gin.New()
+	r.Use(gin.Recovery(), Compression(nil))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := serve(r, req)
+	assertStatus(t, w, http.StatusInternalServerError)
+}
@@ -0,0 +1,32 @@
+package middleware
+
+// Keys used to share request-scoped values through gin.Context
+const (
+	// SubjectKey holds the authenticated subject (e.g. the JWT "sub" claim).
+	// Auth middleware should set it with c.Set(middleware.SubjectKey, claims.Subject).
+	SubjectKey = "subject"
+
+	// RequestIDKey holds the request ID set by RequestID
+	RequestIDKey = "request_id"
+
+	// CSPNonceKey holds the per-request CSP nonce set by SecurityHeaders
+	CSPNonceKey = "csp_nonce"
+
+	// APIKeyKey holds the *apikey.Key authenticated by APIKey
+	APIKeyKey = "api_key"
+
+	// APIVersionKey holds the API version resolved by Versioning
+	APIVersionKey = "api_version"
+
+	// csrfTokenKey holds the CSRF token set by CSRF, see GetCSRFToken
+	csrfTokenKey = "csrf_token"
+
+	// sessionKey holds the *session.Session loaded by Session, see GetSession
+	sessionKey = "session"
+
+	// requestOriginKey holds the client IP, scheme and host resolved by RealIP
+	requestOriginKey = "request_origin"
+
+	// capturedBodiesKey holds the capture state set by BodyCapture
+	capturedBodiesKey = "captured_bodies"
+)
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+)
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowedOrigins lists exact origins ("https://example.com"), wildcard
+	// subdomain patterns ("https://*.example.com") or "*" for any origin
+	AllowedOrigins []string
+	// AllowedOriginRegexes matches origins against regular expressions,
+	// e.g. regexp.MustCompile(`^https://pr-\d+\.preview\.example\.com$`)
+	AllowedOriginRegexes []*regexp.Regexp
+	// AllowOriginFunc is consulted when no pattern matches
+	AllowOriginFunc func(origin string) bool
+
+	AllowedMethods []string // e.g. GET, POST, PUT, DELETE
+	AllowedHeaders []string // e.g. Origin, Content-Type, Authorization; "*" allows any
+	ExposedHeaders []string // response headers readable by browser scripts
+
+	AllowCredentials    bool          // origin is reflected instead of "*" when enabled
+	AllowPrivateNetwork bool          // answer Private Network Access preflights
+	MaxAge              time.Duration // how long browsers may cache preflight results
+}
+
+// DefaultCORSConfig allows any origin without credentials
+func DefaultCORSConfig() *CORSConfig {
+	return &CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Origin", "Content-Type", "Authorization"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// corsPolicy is the precomputed form of a CORSConfig
+type corsPolicy struct {
+	cfg            *CORSConfig
+	allowAll       bool
+	exact          map[string]bool
+	wildcards      [][2]string // prefix and suffix around "*"
+	allowedMethods map[string]bool
+	allowedHeaders map[string]bool
+	allowAnyHeader bool
+	methods        string
+	headers        string
+	exposed        string
+	maxAge         string
+}
+
+func newCORSPolicy(cfg *CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		cfg:            cfg,
+		exact:          make(map[string]bool),
+		allowedMethods: make(map[string]bool),
+		allowedHeaders: make(map[string]bool),
+		methods:        strings.Join(cfg.AllowedMethods, ", "),
+		headers:        strings.Join(cfg.AllowedHeaders, ", "),
+		exposed:        strings.Join(cfg.ExposedHeaders, ", "),
+	}
+
+	for _, o := range cfg.AllowedOrigins {
+		o = strings.ToLower(o)
+		switch {
+		case o == "*":
+			p.allowAll = true
+		case strings.Contains(o, "*"):
+			i := strings.Index(o, "*")
+			p.wildcards = append(p.wildcards, [2]string{o[:i], o[i+1:]})
+		default:
+			p.exact[o] = true
+		}
+	}
+	for _, m := range cfg.AllowedMethods {
+		p.allowedMethods[strings.ToUpper(m)] = true
+	}
+	for _, h := range cfg.AllowedHeaders {
+		if h == "*" {
+			p.allowAnyHeader = true
+		}
+		p.allowedHeaders[strings.ToLower(h)] = true
+	}
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	return p
+}
+
+func (p *corsPolicy) originAllowed(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+
+	lower := strings.ToLower(origin)
+	if p.exact[lower] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		// Require at least one character for the wildcard so "https://.example.com" is rejected
+		if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+	for _, re := range p.cfg.AllowedOriginRegexes {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return p.cfg.AllowOriginFunc != nil && p.cfg.AllowOriginFunc(origin)
+}
+
+func (p *corsPolicy) headersAllowed(requested string) bool {
+	if p.allowAnyHeader || requested == "" {
+		return true
+	}
+	for _, h := range strings.Split(requested, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" && !p.allowedHeaders[h] {
+			return false
+		}
+	}
+	return true
+}
+
+// allowOrigin sets Access-Control-Allow-Origin and Vary for an allowed origin.
+// "*" is never combined with credentials because browsers reject it.
+func (p *corsPolicy) allowOrigin(c *gin.Context, origin string) {
+	if p.allowAll && !p.cfg.AllowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+		return
+	}
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.cfg.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *corsPolicy) varyOnOrigin() bool {
+	return !p.allowAll || p.cfg.AllowCredentials
+}
+
+// CORS handles cross-origin requests and preflights.
+// Requests without an Origin header are passed through untouched, and OPTIONS
+// requests that are not preflights reach the route handlers.
+func CORS(cfg *CORSConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultCORSConfig()
+	}
+	p := newCORSPolicy(cfg)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if preflight {
+			c.Writer.Header().Add("Vary", "Origin")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			if p.cfg.AllowPrivateNetwork {
+				c.Writer.Header().Add("Vary", "Access-Control-Request-Private-Network")
+			}
+		} else if p.varyOnOrigin() {
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !p.originAllowed(origin) {
+			if preflight {
+				abortWithError(c, http.StatusForbidden, constants.Forbidden, "CORS request rejected: origin not allowed", nil)
+				return
+			}
+			// Let the request through without CORS headers, the browser blocks the response
+			c.Next()
+			return
+		}
+
+		if !preflight {
+			p.allowOrigin(c, origin)
+			if p.exposed != "" {
+				c.Header("Access-Control-Expose-Headers", p.exposed)
+			}
+			c.Next()
+			return
+		}
+
+		// ---------------- Preflight ----------------
+		method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+		if !p.allowedMethods[method] {
+			abortWithError(c, http.StatusForbidden, constants.Forbidden, "CORS request rejected: method not allowed", nil)
+			return
+		}
+		requestedHeaders := c.GetHeader("Access-Control-Request-Headers")
+		if !p.headersAllowed(requestedHeaders) {
+			abortWithError(c, http.StatusForbidden, constants.Forbidden, "CORS request rejected: headers not allowed", nil)
+			return
+		}
+
+		p.allowOrigin(c, origin)
+		c.Header("Access-Control-Allow-Methods", p.methods)
+		if p.allowAnyHeader {
+			// "*" is treated literally with credentials, so echo what was requested
+			c.Header("Access-Control-Allow-Headers", requestedHeaders)
+		} else if p.headers != "" {
+			c.Header("Access-Control-Allow-Headers", p.headers)
+		}
+		if p.maxAge != "" {
+			c.Header("Access-Control-Max-Age", p.maxAge)
+		}
+		if p.cfg.AllowPrivateNetwork && c.GetHeader("Access-Control-Request-Private-Network") == "true" {
+			c.Header("Access-Control-Allow-Private-Network", "true")
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
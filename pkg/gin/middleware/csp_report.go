@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// maxCSPReportSize bounds report bodies, browsers send a few KB at most
+const maxCSPReportSize = 64 << 10
+
+// CSPReport is a normalized CSP violation report
+type CSPReport struct {
+	DocumentURI        string `json:"documentURL"`
+	Referrer           string `json:"referrer"`
+	BlockedURI         string `json:"blockedURL"`
+	EffectiveDirective string `json:"effectiveDirective"`
+	OriginalPolicy     string `json:"originalPolicy"`
+	Disposition        string `json:"disposition"` // "enforce" or "report"
+	SourceFile         string `json:"sourceFile"`
+	LineNumber         int    `json:"lineNumber"`
+	ColumnNumber       int    `json:"columnNumber"`
+	StatusCode         int    `json:"statusCode"`
+	Sample             string `json:"sample"`
+}
+
+// legacyCSPReport is the application/csp-report body sent for report-uri
+type legacyCSPReport struct {
+	Body struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		StatusCode         int    `json:"status-code"`
+		ScriptSample       string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is one entry of an application/reports+json body sent for report-to
+type reportingAPIReport struct {
+	Type string    `json:"type"`
+	Body CSPReport `json:"body"`
+}
+
+// CSPReportHandler receives violation reports from both report-uri and report-to.
+// onReport defaults to logging a warning. Always responds 204 so browsers don't retry.
+//
+//	r.POST("/csp-report", middleware.CSPReportHandler(nil))
+//	cfg.CSP.ReportURI("/csp-report")
+func CSPReportHandler(onReport func(c *gin.Context, report CSPReport)) gin.HandlerFunc {
+	if onReport == nil {
+		onReport = func(c *gin.Context, r CSPReport) {
+			logger.Warn("CSP violation (%s): %s blocked %q on %s", r.Disposition, r.EffectiveDirective, r.BlockedURI, r.DocumentURI)
+		}
+	}
+
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCSPReportSize))
+		if err != nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		for _, report := range parseCSPReports(body) {
+			onReport(c, report)
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func parseCSPReports(body []byte) []CSPReport {
+	var legacy legacyCSPReport
+	if err := json.Unmarshal(body, &legacy); err == nil && legacy.Body.DocumentURI != "" {
+		b := legacy.Body
+		directive := b.EffectiveDirective
+		if directive == "" {
+			directive = b.ViolatedDirective
+		}
+		return []CSPReport{{
+			DocumentURI:        b.DocumentURI,
+			Referrer:           b.Referrer,
+			BlockedURI:         b.BlockedURI,
+			EffectiveDirective: directive,
+			OriginalPolicy:     b.OriginalPolicy,
+			Disposition:        b.Disposition,
+			SourceFile:         b.SourceFile,
+			LineNumber:         b.LineNumber,
+			ColumnNumber:       b.ColumnNumber,
+			StatusCode:         b.StatusCode,
+			Sample:             b.ScriptSample,
+		}}
+	}
+
+	var batch []reportingAPIReport
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil
+	}
+	reports := make([]CSPReport, 0, len(batch))
+	for _, r := range batch {
+		if r.Type == "csp-violation" {
+			reports = append(reports, r.Body)
+		}
+	}
+	return reports
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSPBuild(t *testing.T) {
+	p := NewCSP().DefaultSrc(CSPSelf).ScriptSrc(CSPSelf, CSPNonce).ObjectSrc(CSPNone)
+
+	if got, want := p.Build("abc"), "default-src 'self'; script-src 'self' 'nonce-abc'; object-src 'none'"; got != want {
+		t.Fatalf("Build = %q, want %q", got, want)
+	}
+	if got, want := p.String(), "default-src 'self'; script-src 'self'; object-src 'none'"; got != want {
+		t.Fatalf("String = %q, want %q", got, want)
+	}
+
+	clone := p.Clone().Add("script-src", CSPStrictDynamic).Remove("object-src")
+	if strings.Contains(p.String(), CSPStrictDynamic) || !strings.Contains(p.String(), "object-src") {
+		t.Fatalf("changing a clone changed the original: %q", p.String())
+	}
+	if got, want := clone.String(), "default-src 'self'; script-src 'self' 'strict-dynamic'"; got != want {
+		t.Fatalf("clone = %q, want %q", got, want)
+	}
+}
+
+func TestSecurityHeadersNoncePerRequest(t *testing.T) {
+	r := newTestRouter(SecurityHeaders(HTMLSecurityConfig()))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, GetCSPNonce(c))
+	})
+
+	seen := make(map[string]bool)
+	for range 3 {
+		w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+		nonce := w.Body.String()
+		if nonce == "" || seen[nonce] {
+			t.Fatalf("nonce %q is empty or reused", nonce)
+		}
+		seen[nonce] = true
+		if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "'nonce-"+nonce+"'") {
+			t.Fatalf("Content-Security-Policy %q does not carry the nonce %q", csp, nonce)
+		}
+	}
+}
+
+func TestSecurityHeadersCSPReportOnly(t *testing.T) {
+	cfg := APISecurityConfig()
+	cfg.CSPReportOnly = true
+	r := newTestRouter(SecurityHeaders(cfg))
+	r.GET("/", ok)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Header().Get("Content-Security-Policy") != "" || w.Header().Get("Content-Security-Policy-Report-Only") == "" {
+		t.Fatalf("CSP sent as enforced: %v", w.Header())
+	}
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			"report-uri",
+			`{"csp-report":{"document-uri":"https://example.com/","blocked-uri":"inline","violated-directive":"script-src"}}`,
+			[]string{"script-src"},
+		},
+		{
+			"reporting api",
+			`[{"type":"csp-violation","body":{"documentURL":"https://example.com/","effectiveDirective":"img-src"}},{"type":"deprecation","body":{}}]`,
+			[]string{"img-src"},
+		},
+		{"malformed", `not json`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			r := newTestRouter()
+			r.POST("/csp-report", CSPReportHandler(func(c *gin.Context, report CSPReport) {
+				got = append(got, report.EffectiveDirective)
+			}))
+
+			w := serve(r, httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(tt.body)))
+			assertStatus(t, w, http.StatusNoContent)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("reports = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
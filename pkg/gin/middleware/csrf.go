@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+)
+
+// CSRFConfig configures the CSRF middleware
+type CSRFConfig struct {
+	Secret []byte // HMAC key for signing tokens, required
+
+	CookieName   string        // defaults to "csrf_token"
+	CookiePath   string        // defaults to "/"
+	CookieDomain string        // empty scopes the cookie to the current host
+	MaxAge       time.Duration // cookie lifetime, defaults to 12h
+	SameSite     http.SameSite // defaults to http.SameSiteLaxMode
+	// InsecureCookie drops the Secure flag, for local development over plain HTTP
+	InsecureCookie bool
+	// HTTPOnly hides the cookie from scripts. Leave it off for SPAs that read the
+	// cookie; server-rendered pages can embed GetCSRFToken instead.
+	HTTPOnly bool
+
+	HeaderName string // defaults to "X-CSRF-Token"
+	FormField  string // defaults to "csrf_token", checked for form posts without the header
+
+	SafeMethods []string // not checked, defaults to GET, HEAD, OPTIONS and TRACE
+	ExemptPaths []string // not checked, e.g. webhooks; a trailing "*" matches a prefix
+	// TrustedOrigins may send unsafe requests besides the request's own host,
+	// e.g. "https://admin.example.com"
+	TrustedOrigins []string
+
+	// SessionIDFunc binds tokens to the caller's session so a token issued to
+	// one session is rejected in another. Optional.
+	SessionIDFunc func(c *gin.Context) string
+}
+
+// CSRF protects cookie-authenticated routes with signed double-submit cookies.
+// Every request gets a token cookie signed with Secret; unsafe requests must echo
+// it in HeaderName or FormField, and their Origin (or Referer) must be the
+// request's host or a trusted origin. Failures respond 403 CSRFTokenInvalid.
+//
+// Routes authenticated with bearer tokens or API keys don't need it.
+func CSRF(cfg *CSRFConfig) gin.HandlerFunc {
+	if cfg == nil || len(cfg.Secret) == 0 {
+		panic("middleware: CSRFConfig.Secret is required")
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "csrf_token"
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 12 * time.Hour
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	if cfg.FormField == "" {
+		cfg.FormField = "csrf_token"
+	}
+	if len(cfg.SafeMethods) == 0 {
+		cfg.SafeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace}
+	}
+
+	exempt := newPathMatcher(cfg.ExemptPaths)
+	trusted := make(map[string]bool)
+	for _, o := range cfg.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+
+	return func(c *gin.Context) {
+		sessionID := ""
+		if cfg.SessionIDFunc != nil {
+			sessionID = cfg.SessionIDFunc(c)
+		}
+
+		token := ""
+		if cookie, err := c.Cookie(cfg.CookieName); err == nil && verifyCSRFToken(cfg.Secret, cookie, sessionID) {
+			token = cookie
+		} else {
+			token = newCSRFToken(cfg.Secret, sessionID)
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     cfg.CookieName,
+				Value:    token,
+				Path:     cfg.CookiePath,
+				Domain:   cfg.CookieDomain,
+				MaxAge:   int(cfg.MaxAge.Seconds()),
+				Secure:   !cfg.InsecureCookie,
+				HttpOnly: cfg.HTTPOnly,
+				SameSite: cfg.SameSite,
+			})
+		}
+		c.Set(csrfTokenKey, token)
+
+		if slices.Contains(cfg.SafeMethods, c.Request.Method) || exempt.match(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		if !sameOriginRequest(c.Request, RequestScheme(c)+"://"+RequestHost(c), trusted) {
+			abortWithError(c, http.StatusForbidden, constants.CSRFTokenInvalid, "CSRF check failed: origin not allowed", nil)
+			return
+		}
+
+		sent := c.GetHeader(cfg.HeaderName)
+		if sent == "" {
+			sent = c.PostForm(cfg.FormField)
+		}
+		// The cookie was replaced above if its signature was invalid, so a
+		// match also proves the submitted token was signed for this session
+		if sent == "" || !hmac.Equal([]byte(sent), []byte(token)) {
+			abortWithError(c, http.StatusForbidden, constants.CSRFTokenInvalid, "CSRF check failed: missing or invalid token", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetCSRFToken returns the token to embed in forms or send in the CSRF header
+func GetCSRFToken(c *gin.Context) string {
+	return c.GetString(csrfTokenKey)
+}
+
+// newCSRFToken returns "<random>.<signature>", base64url encoded
+func newCSRFToken(secret []byte, sessionID string) string {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		panic("middleware: failed to generate CSRF token: " + err.Error())
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(nonce)
+	return encoded + "." + signCSRFToken(secret, encoded, sessionID)
+}
+
+func verifyCSRFToken(secret []byte, token, sessionID string) bool {
+	nonce, sig, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(signCSRFToken(secret, nonce, sessionID)))
+}
+
+func signCSRFToken(secret []byte, nonce, sessionID string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(nonce + "|" + sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// sameOriginRequest checks Origin, falling back to Referer, against self
+// ("scheme://host") and the trusted origins. The scheme is compared too, so an
+// http:// page can't post to the https:// site. Requests with neither header
+// are allowed since browsers send at least one on cross-site requests.
+func sameOriginRequest(r *http.Request, self string, trusted map[string]bool) bool {
+	source := r.Header.Get("Origin")
+	if source == "null" {
+		// Sent by sandboxed frames and redirects across origins
+		return false
+	}
+	if source == "" {
+		source = r.Header.Get("Referer")
+		if source == "" {
+			return true
+		}
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	return trusted[origin] || origin == strings.ToLower(self)
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCSRFRouter() *gin.Engine {
+	r := newTestRouter(CSRF(&CSRFConfig{
+		Secret:         []byte("0123456789abcdef0123456789abcdef"),
+		ExemptPaths:    []string{"/webhooks/*"},
+		TrustedOrigins: []string{"https://admin.example.com"},
+	}))
+	r.GET("/form", func(c *gin.Context) { c.String(http.StatusOK, GetCSRFToken(c)) })
+	r.POST("/orders", ok)
+	r.POST("/webhooks/stripe", ok)
+	return r
+}
+
+func csrfCookie(t *testing.T, r *gin.Engine) (*http.Cookie, string) {
+	t.Helper()
+	w := serve(r, httptest.NewRequest(http.MethodGet, "http://example.com/form", nil))
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "csrf_token" {
+			return c, w.Body.String()
+		}
+	}
+	t.Fatal("no CSRF cookie set")
+	return nil, ""
+}
+
+func TestCSRF(t *testing.T) {
+	r := newCSRFRouter()
+	cookie, token := csrfCookie(t, r)
+	if cookie.Value != token {
+		t.Fatal("GetCSRFToken differs from the cookie")
+	}
+
+	post := func(origin, header string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/orders", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return serve(r, req)
+	}
+
+	assertStatus(t, post("http://example.com", token, cookie), http.StatusOK)
+	assertStatus(t, post("https://admin.example.com", token, cookie), http.StatusOK)
+	assertStatus(t, post("", token, cookie), http.StatusOK) // no Origin or Referer, e.g. old clients
+	assertStatus(t, post("https://evil.example.com", token, cookie), http.StatusForbidden)
+	assertStatus(t, post("null", token, cookie), http.StatusForbidden)
+	assertStatus(t, post("https://example.com", token, cookie), http.StatusForbidden) // request was plain http
+	assertStatus(t, post("http://example.com", "", cookie), http.StatusForbidden)
+	assertStatus(t, post("http://example.com", token), http.StatusForbidden)
+
+	// A token the attacker made up, sent as both cookie and header
+	forged := &http.Cookie{Name: "csrf_token", Value: "AAAA.BBBB"}
+	assertStatus(t, post("http://example.com", forged.Value, forged), http.StatusForbidden)
+}
+
+func TestCSRFFormFieldAndExemptPaths(t *testing.T) {
+	r := newCSRFRouter()
+	cookie, token := csrfCookie(t, r)
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/orders", strings.NewReader("csrf_token="+token))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	assertStatus(t, serve(r, req), http.StatusOK)
+
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodPost, "http://example.com/webhooks/stripe", nil)), http.StatusOK)
+}
+
+func TestCSRFComparesScheme(t *testing.T) {
+	r := newTestRouter(RealIP(nil), CSRF(&CSRFConfig{Secret: []byte("0123456789abcdef0123456789abcdef")}))
+	r.GET("/form", func(c *gin.Context) { c.String(http.StatusOK, GetCSRFToken(c)) })
+	r.POST("/orders", ok)
+	cookie, token := csrfCookie(t, r)
+
+	post := func(origin string) *httptest.ResponseRecorder {
+		// Behind a TLS-terminating load balancer
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/orders", nil)
+		req.RemoteAddr = "10.0.0.5:4321"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("Origin", origin)
+		req.Header.Set("X-CSRF-Token", token)
+		req.AddCookie(cookie)
+		return serve(r, req)
+	}
+	assertStatus(t, post("https://example.com"), http.StatusOK)
+	assertStatus(t, post("http://example.com"), http.StatusForbidden)
+	assertStatus(t, post("https://example.com:8443"), http.StatusForbidden)
+}
@@ -20,18 +20,7 @@ func ErrorHandler() gin.HandlerFunc {
 		}
 
 		lastErr := c.Errors.Last().Err
-
-		// Default response
-		status := http.StatusInternalServerError
-		message := "Internal Server Error"
-		code := constants.Empty
-
-		// If it's a CodedError, use its HTTPCode, Message, and Code
-		if codedErr, ok := lastErr.(*apperr.CodedError); ok {
-			status = codedErr.HTTPCode
-			message = codedErr.Message
-			code = codedErr.Code
-		}
+		status, message, code := resolveError(lastErr)
 
 		// Color coding for logs
 		statusColor := constants.ColorGreen
@@ -46,26 +35,82 @@ func ErrorHandler() gin.HandlerFunc {
 			logCode = fmt.Sprintf(" | Code: %s%d%s", constants.ColorYellow, code, constants.ColorReset)
 		}
 
-		logger.Error("%sRequest %s %s -> %s%d%s%s | Error: %s%v%s",
+		// Attach request/response bodies when BodyCapture is registered
+		logBodies := ""
+		if bodies, ok := GetCapturedBodies(c, status); ok {
+			if bodies.Request != "" {
+				logBodies += fmt.Sprintf(" | Request: %s%s", bodies.Request, truncatedMark(bodies.RequestTruncated))
+			}
+			if bodies.Response != "" {
+				logBodies += fmt.Sprintf(" | Response: %s%s", bodies.Response, truncatedMark(bodies.ResponseTruncated))
+			}
+		}
+
+		logger.Error("%sRequest %s %s -> %s%d%s%s | Error: %s%v%s%s",
 			constants.ColorBlue,
 			c.Request.Method,
 			c.Request.URL.Path,
 			statusColor, status, constants.ColorReset,
 			logCode,
 			constants.ColorRed, lastErr, constants.ColorReset,
+			logBodies,
 		)
 
 		// Respond with structured JSON
-		resp := gin.H{
-			"success": false,
-			"message": message,
-		}
+		c.JSON(status, errorResponse(message, code))
 
-		if code != 0 {
-			resp["code"] = code
-		}
+	}
+}
 
-		c.JSON(status, resp)
+func truncatedMark(truncated bool) string {
+	if truncated {
+		return " [truncated]"
+	}
+	return ""
+}
 
+// abortWithError records a CodedError for ErrorHandler and stops the chain
+func abortWithError(c *gin.Context, status, code int, message string, err error) {
+	c.Error(apperr.NewError(apperr.ErrorParams{
+		HTTPCode: status,
+		Code:     code,
+		Message:  message,
+		Err:      err,
+	}))
+	c.Abort()
+}
+
+// errorResponse is the JSON body ErrorHandler responds with
+func errorResponse(message string, code int) gin.H {
+	resp := gin.H{
+		"success": false,
+		"message": message,
+	}
+
+	if code != 0 {
+		resp["code"] = code
+	}
+	return resp
+}
+
+// resolveError maps an error to the status, message and code ErrorHandler responds with
+func resolveError(err error) (status int, message string, code int) {
+	// If it's a CodedError, use its HTTPCode, Message, and Code
+	if codedErr, ok := err.(*apperr.CodedError); ok {
+		return codedErr.HTTPCode, codedErr.Message, codedErr.Code
+	}
+	return http.StatusInternalServerError, "Internal Server Error", constants.Empty
+}
+
+// responseStatus returns the status the client will receive, including errors
+// that ErrorHandler has not written yet
+func responseStatus(c *gin.Context) (status int, code int) {
+	if len(c.Errors) > 0 && !c.Writer.Written() {
+		status, _, code = resolveError(c.Errors.Last().Err)
+		return status, code
+	}
+	if len(c.Errors) > 0 {
+		_, _, code = resolveError(c.Errors.Last().Err)
 	}
+	return c.Writer.Status(), code
 }
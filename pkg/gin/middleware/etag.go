@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+)
+
+// ETagConfig configures the ETag middleware
+type ETagConfig struct {
+	// Weak makes generated ETags weak (W/"..."), for bodies that are
+	// semantically but not byte-for-byte identical between requests
+	Weak bool
+	// CacheControl replaces a missing or "no-store" Cache-Control on responses
+	// with an ETag, so clients keep and revalidate them. Defaults to "no-cache".
+	CacheControl string
+	// RequireIfMatchMethods lists methods rejected with 428 when they have no
+	// If-Match header, e.g. PUT and PATCH for optimistic concurrency
+	RequireIfMatchMethods []string
+	SkipPaths             []string // exact paths, or prefixes ending in "*"
+}
+
+// DefaultETagConfig generates strong ETags and asks clients to revalidate
+func DefaultETagConfig() *ETagConfig {
+	return &ETagConfig{CacheControl: "no-cache"}
+}
+
+// ETag adds ETags to successful GET and HEAD responses and answers conditional
+// requests with 304 Not Modified. Handlers can supply the ETag with SetETag,
+// e.g. from a version column, otherwise one is computed from the body.
+// Last-Modified set with SetLastModified is checked against If-Modified-Since
+// when the request has no If-None-Match.
+//
+// GET and HEAD responses are buffered in full so they can be hashed and
+// replaced with a 304; add routes that stream or serve large files to SkipPaths.
+//
+// Register it after ErrorHandler and SecurityHeaders, and inside Compression.
+func ETag(cfg *ETagConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultETagConfig()
+	}
+	if cfg.CacheControl == "" {
+		cfg.CacheControl = "no-cache"
+	}
+	skip := newPathMatcher(cfg.SkipPaths)
+
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if skip.match(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		if slices.Contains(cfg.RequireIfMatchMethods, method) && c.GetHeader("If-Match") == "" {
+			abortWithError(c, http.StatusPreconditionRequired, constants.PreconditionRequired,
+				"If-Match header is required", nil)
+			return
+		}
+		if method != http.MethodGet && method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := newBufferedWriter(original)
+		c.Writer = buffered
+		done := false
+		defer func() {
+			// Also runs when a handler panics, so Recovery's response reaches the client
+			c.Writer = original
+			if !done {
+				buffered.discard()
+			}
+		}()
+
+		c.Next()
+
+		done = true
+		c.Writer = original
+
+		if buffered.Status() != http.StatusOK || len(c.Errors) > 0 {
+			buffered.flush()
+			return
+		}
+
+		h := buffered.Header()
+		etag := h.Get("ETag")
+		if etag == "" && method == http.MethodGet {
+			etag = computeETag(buffered.bytes(), cfg.Weak)
+			h.Set("ETag", etag)
+		}
+		if etag != "" {
+			if cc := h.Get("Cache-Control"); cc == "" || cc == "no-store" {
+				h.Set("Cache-Control", cfg.CacheControl)
+			}
+		}
+
+		if notModified(c.Request, etag, h.Get("Last-Modified")) {
+			for _, k := range []string{"Content-Type", "Content-Length", "Content-Encoding"} {
+				h.Del(k)
+			}
+			buffered.replace(http.StatusNotModified, nil)
+		}
+		buffered.flush()
+	}
+}
+
+// SetETag sets the response ETag from a handler-supplied version
+func SetETag(c *gin.Context, version string, weak bool) {
+	etag := `"` + version + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+	c.Header("ETag", etag)
+}
+
+// SetLastModified sets Last-Modified for If-Modified-Since checks
+func SetLastModified(c *gin.Context, t time.Time) {
+	c.Header("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// CheckIfMatch guards an update with the resource's current version, the
+// same value handlers pass to SetETag. Without If-Match it passes unless
+// required, in which case it responds 428. It responds 412 when none of the
+// listed ETags match. Returns false when the request was aborted.
+//
+// ETags are compared strongly as RFC 9110 requires for If-Match, so weak
+// ETags never match. Compression weakens the ETags of the responses it
+// compresses; add resources updated with If-Match to its SkipPaths.
+func CheckIfMatch(c *gin.Context, version string, required bool) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if required {
+			abortWithError(c, http.StatusPreconditionRequired, constants.PreconditionRequired,
+				"If-Match header is required", nil)
+			return false
+		}
+		return true
+	}
+
+	if !etagListMatches(header, `"`+version+`"`, true) {
+		abortWithError(c, http.StatusPreconditionFailed, constants.PreconditionFailed,
+			"resource has been modified, fetch it again and retry", nil)
+		return false
+	}
+	return true
+}
+
+// computeETag hashes the body into a quoted entity tag
+func computeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+	return etag
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when it is absent
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagListMatches(inm, etag, false)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// etagListMatches reports whether a comma separated If-Match/If-None-Match
+// list contains etag or "*". Strong comparison (If-Match) requires both tags
+// to be strong and identical, weak comparison (If-None-Match) ignores W/.
+func etagListMatches(list, etag string, strong bool) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strong && strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
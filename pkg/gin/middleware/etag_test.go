@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestETagConditionalGet(t *testing.T) {
+	r := newTestRouter(ETag(nil))
+	r.GET("/", ok)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertStatus(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("headers = %v", w.Header())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	w = serve(r, req)
+	assertStatus(t, w, http.StatusNotModified)
+	if w.Body.Len() != 0 {
+		t.Fatal("304 has a body")
+	}
+}
+
+func TestCheckIfMatchUsesStrongComparison(t *testing.T) {
+	r := newTestRouter()
+	r.PUT("/", func(c *gin.Context) {
+		if CheckIfMatch(c, "v2", true) {
+			ok(c)
+		}
+	})
+
+	for _, tc := range []struct {
+		ifMatch string
+		want    int
+	}{
+		{"", http.StatusPreconditionRequired},
+		{`"v2"`, http.StatusOK},
+		{`"v1", "v2"`, http.StatusOK},
+		{`*`, http.StatusOK},
+		{`"v1"`, http.StatusPreconditionFailed},
+		{`W/"v2"`, http.StatusPreconditionFailed},
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+		w := serve(r, req)
+		if w.Code != tc.want {
+			t.Errorf("If-Match %q: status = %d, want %d", tc.ifMatch, w.Code, tc.want)
+		}
+	}
+}
+
+func TestETagLetsRecoveryRespondToPanics(t *testing.T) {
+	r := gin.New()
+	r.Use(gin.Recovery(), ETag(nil))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, "/", nil)), http.StatusInternalServerError)
+}
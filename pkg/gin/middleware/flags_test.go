@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	"github.com/nhstop/go-utils/pkg/flags"
+)
+
+func assertCode(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	var body struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != want {
+		t.Fatalf("code = %d, want %d", body.Code, want)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	src := flags.NewMemorySource()
+	r := newTestRouter(Maintenance(&MaintenanceConfig{Source: src}))
+	r.GET("/orders", ok)
+	r.POST("/orders", ok)
+
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodPost, "/orders", nil)), http.StatusOK)
+
+	src.Enable("maintenance")
+	w := serve(r, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	assertStatus(t, w, http.StatusServiceUnavailable)
+	assertCode(t, w, constants.MaintenanceMode)
+	if got := w.Header().Get("Retry-After"); got != "300" {
+		t.Fatalf("Retry-After = %q, want 300", got)
+	}
+	// Reads keep working
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, "/orders", nil)), http.StatusOK)
+
+	src.Disable("maintenance")
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodPost, "/orders", nil)), http.StatusOK)
+
+	assertPanics(t, "nil source", func() { Maintenance(nil) })
+}
+
+func TestRequireFlag(t *testing.T) {
+	src := flags.NewMemorySource(flags.Flag{Name: "beta", Percentage: 50})
+	r := newTestRouter(func(c *gin.Context) {
+		if sub := c.GetHeader("X-Subject"); sub != "" {
+			c.Set(SubjectKey, sub)
+		}
+	}, RequireFlag(src, "beta"))
+	r.GET("/beta", ok)
+
+	request := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/beta", nil)
+		req.Header.Set("X-Subject", subject)
+		return serve(r, req)
+	}
+
+	w := request("")
+	assertStatus(t, w, http.StatusNotFound)
+	assertCode(t, w, constants.FeatureDisabled)
+
+	// Each subject gets the same answer every time, and some get each answer
+	seen := map[int]bool{}
+	for i := range 50 {
+		subject := fmt.Sprintf("user-%d", i)
+		first := request(subject).Code
+		if again := request(subject).Code; again != first {
+			t.Fatalf("%s got %d then %d", subject, first, again)
+		}
+		seen[first] = true
+	}
+	if !seen[http.StatusOK] || !seen[http.StatusNotFound] {
+		t.Fatalf("statuses seen = %v, want both 200 and 404 at 50%%", seen)
+	}
+
+	src.Enable("beta")
+	assertStatus(t, request(""), http.StatusOK)
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+	"github.com/nhstop/go-utils/pkg/idempotency"
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// IdempotencyConfig configures the Idempotency middleware
+type IdempotencyConfig struct {
+	Store       idempotency.Store
+	Header      string        // defaults to "Idempotency-Key"
+	Methods     []string      // defaults to POST and PATCH
+	TTL         time.Duration // how long completed responses are replayed, defaults to 24h
+	LockTimeout time.Duration // how long an in-flight request holds its key, defaults to 1m
+	Required    bool          // reject requests without a key
+	MaxBytes    int64         // largest body read for the fingerprint, defaults to 1MB
+}
+
+// idempotentSkipHeaders are never stored or replayed
+var idempotentSkipHeaders = []string{"Set-Cookie", "Date", "Content-Length"}
+
+// Idempotency makes retries of POST/PATCH requests safe. The first request
+// with a given Idempotency-Key runs normally and its response is stored;
+// retries with the same key and body get the stored response replayed, retries
+// while the first is still running get 409, and reusing a key with a different
+// request gets 422. Keys are scoped to the authenticated subject when set.
+// Responses with 5xx statuses are not stored so the client can retry. Only
+// headers the handler set are stored; those from earlier middleware, such as
+// X-Request-ID, are left to each request.
+// Bodies larger than MaxBytes are rejected with 413.
+//
+// Register it after ErrorHandler.
+func Idempotency(cfg *IdempotencyConfig) gin.HandlerFunc {
+	if cfg == nil || cfg.Store == nil {
+		panic("middleware: IdempotencyConfig.Store is required")
+	}
+	if cfg.Header == "" {
+		cfg.Header = "Idempotency-Key"
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 1 << 20
+	}
+
+	return func(c *gin.Context) {
+		if !slices.Contains(cfg.Methods, c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(cfg.Header)
+		if key == "" {
+			if cfg.Required {
+				abortWithError(c, http.StatusBadRequest, constants.InvalidRequest, cfg.Header+" header is required", nil)
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			abortWithError(c, http.StatusBadRequest, constants.InvalidRequest, cfg.Header+" header is too long", nil)
+			return
+		}
+		if sub := c.GetString(SubjectKey); sub != "" {
+			key = sub + ":" + key
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBytes))
+		if err != nil {
+			c.Error(apperr.BadRequest(err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c, body)
+
+		ctx := c.Request.Context()
+		rec, err := cfg.Store.Begin(ctx, key, fingerprint, cfg.LockTimeout)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, constants.InternalServer, "failed to process idempotency key", err)
+			return
+		}
+
+		if rec != nil {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				abortWithError(c, http.StatusUnprocessableEntity, constants.IdempotencyKeyMismatch,
+					cfg.Header+" was already used with a different request", nil)
+			case !rec.Completed:
+				abortWithError(c, http.StatusConflict, constants.IdempotencyKeyInUse,
+					"a request with this "+cfg.Header+" is still being processed", nil)
+			default:
+				replayResponse(c, rec)
+			}
+			return
+		}
+
+		// ---------------- First request for this key ----------------
+		original := c.Writer
+		// Headers set by earlier middleware belong to each request, not the stored response
+		before := original.Header().Clone()
+		buffered := newBufferedWriter(original)
+		c.Writer = buffered
+		done := false
+		defer func() {
+			// A panicking handler stores nothing, the key is released and
+			// Recovery's response reaches the client
+			c.Writer = original
+			if !done {
+				buffered.discard()
+				if err := cfg.Store.Release(context.WithoutCancel(ctx), key); err != nil {
+					logger.Error("failed to release idempotency key: %v", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		done = true
+		c.Writer = original
+
+		status, respBody := buffered.Status(), buffered.bytes()
+		header := changedHeaders(before, buffered.Header())
+		if len(c.Errors) > 0 && !buffered.Written() {
+			// ErrorHandler will write this error, store the same body it sends
+			var message string
+			var code int
+			status, message, code = resolveError(c.Errors.Last().Err)
+			respBody, _ = json.Marshal(errorResponse(message, code))
+			header = http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}
+		}
+		for _, h := range idempotentSkipHeaders {
+			header.Del(h)
+		}
+
+		// Store the outcome even if the client has gone away
+		ctx = context.WithoutCancel(ctx)
+		if status >= 500 {
+			if err := cfg.Store.Release(ctx, key); err != nil {
+				logger.Error("failed to release idempotency key: %v", err)
+			}
+		} else if err := cfg.Store.Complete(ctx, key, status, header, respBody, cfg.TTL); err != nil {
+			logger.Error("failed to store idempotent response: %v", err)
+		}
+
+		buffered.flush()
+	}
+}
+
+// requestFingerprint identifies a request by method, path and body
+func requestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// changedHeaders returns the headers in after that were added or changed since before
+func changedHeaders(before, after http.Header) http.Header {
+	changed := make(http.Header)
+	for k, v := range after {
+		if !slices.Equal(before[k], v) {
+			changed[k] = slices.Clone(v)
+		}
+	}
+	return changed
+}
+
+func replayResponse(c *gin.Context, rec *idempotency.Record) {
+	for k, v := range rec.Header {
+		c.Writer.Header()[k] = v
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Status(rec.Status)
+	if len(rec.Body) > 0 {
+		c.Writer.Write(rec.Body)
+	}
+	c.Abort()
+}
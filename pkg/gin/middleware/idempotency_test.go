@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/idempotency"
+)
+
+// memoryIdempotencyStore is an in-memory idempotency.Store for tests
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*idempotency.Record)}
+}
+
+func (s *memoryIdempotencyStore) Begin(_ context.Context, key, fingerprint string, lockTimeout time.Duration) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok {
+		return rec, nil
+	}
+	s.records[key] = &idempotency.Record{Key: key, Fingerprint: fingerprint, ExpiresAt: time.Now().Add(lockTimeout)}
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key string, status int, header http.Header, body []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.records[key]
+	rec.Completed, rec.Status, rec.Header, rec.Body = true, status, header, body
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	return req
+}
+
+func TestIdempotencyReplaysAndRejectsMismatches(t *testing.T) {
+	calls := 0
+	r := newTestRouter(Idempotency(&IdempotencyConfig{Store: newMemoryIdempotencyStore()}))
+	r.POST("/orders", func(c *gin.Context) {
+		calls++
+		c.String(http.StatusCreated, "created")
+	})
+
+	assertStatus(t, serve(r, idempotentRequest("k1", `{"a":1}`)), http.StatusCreated)
+	w := serve(r, idempotentRequest("k1", `{"a":1}`))
+	assertStatus(t, w, http.StatusCreated)
+	if w.Header().Get("Idempotent-Replayed") != "true" || w.Body.String() != "created" || calls != 1 {
+		t.Fatalf("not replayed: calls=%d body=%q", calls, w.Body.String())
+	}
+	assertStatus(t, serve(r, idempotentRequest("k1", `{"a":2}`)), http.StatusUnprocessableEntity)
+}
+
+func TestIdempotencyLimitsBodySize(t *testing.T) {
+	r := newTestRouter(Idempotency(&IdempotencyConfig{Store: newMemoryIdempotencyStore(), MaxBytes: 8}))
+	r.POST("/orders", ok)
+
+	assertStatus(t, serve(r, idempotentRequest("k1", strings.Repeat("x", 9))), http.StatusRequestEntityTooLarge)
+}
+
+func TestIdempotencyPanicReleasesKey(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	panics := true
+	r := gin.New()
+	r.Use(gin.Recovery(), ErrorHandler(), Idempotency(&IdempotencyConfig{Store: store}))
+	r.POST("/orders", func(c *gin.Context) {
+		if panics {
+			c.String(http.StatusOK, "partial")
+			panic("boom")
+		}
+		ok(c)
+	})
+
+	assertStatus(t, serve(r, idempotentRequest("k1", "{}")), http.StatusInternalServerError)
+	panics = false
+	w := serve(r, idempotentRequest("k1", "{}"))
+	assertStatus(t, w, http.StatusOK)
+	if w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("response of the panicking request was stored")
+	}
+}
+
+func TestIdempotencyReplaysOnlyHandlerHeaders(t *testing.T) {
+	r := newTestRouter(RequestID(), Idempotency(&IdempotencyConfig{Store: newMemoryIdempotencyStore()}))
+	r.POST("/orders", func(c *gin.Context) {
+		c.Header("Location", "/orders/1")
+		c.String(http.StatusCreated, "created")
+	})
+
+	first := idempotentRequest("k1", `{"a":1}`)
+	first.Header.Set(RequestIDHeader, "first-request")
+	assertStatus(t, serve(r, first), http.StatusCreated)
+
+	retry := idempotentRequest("k1", `{"a":1}`)
+	retry.Header.Set(RequestIDHeader, "second-request")
+	w := serve(r, retry)
+	if w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("response was not replayed")
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "second-request" {
+		t.Fatalf("%s = %q, want the retry's own ID", RequestIDHeader, got)
+	}
+	if got := w.Header().Get("Location"); got != "/orders/1" {
+		t.Fatalf("Location = %q, want the stored handler header", got)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func ipFilterRequest(peer, forwardedFor string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.RemoteAddr = peer + ":4321"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	return req
+}
+
+func TestIPFilterAllowed(t *testing.T) {
+	f, err := NewIPFilter(&IPFilterConfig{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+		Deny:  []string{"10.9.0.0/16"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"10.1.2.3":    true,
+		"10.9.1.1":    false,
+		"2001:db8::1": true,
+		"192.0.2.1":   false,
+		"not-an-ip":   false,
+		"":            false,
+	}
+	for ip, want := range tests {
+		if got := f.Allowed(ip); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestIPFilterIgnoresForwardedForWithoutRealIP(t *testing.T) {
+	f, err := NewIPFilter(&IPFilterConfig{Allow: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRouter(f.Middleware(), ok)
+
+	assertStatus(t, serve(r, ipFilterRequest("203.0.113.9", "10.1.2.3")), http.StatusForbidden)
+	assertStatus(t, serve(r, ipFilterRequest("10.1.2.3", "")), http.StatusOK)
+}
+
+func TestIPFilterUsesRealIPBehindProxy(t *testing.T) {
+	f, err := NewIPFilter(&IPFilterConfig{Allow: []string{"198.51.100.0/24"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRouter(RealIP(nil), f.Middleware(), ok)
+
+	assertStatus(t, serve(r, ipFilterRequest("10.0.0.5", "198.51.100.7")), http.StatusOK)
+	assertStatus(t, serve(r, ipFilterRequest("10.0.0.5", "198.51.100.7, 203.0.113.9")), http.StatusForbidden)
+	assertStatus(t, serve(r, ipFilterRequest("203.0.113.9", "198.51.100.7")), http.StatusForbidden)
+}
+
+func TestIPFilterUpdate(t *testing.T) {
+	f, err := NewIPFilter(&IPFilterConfig{Allow: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Update(&IPFilterConfig{Allow: []string{"bad"}}); err == nil {
+		t.Fatal("Update accepted a malformed CIDR")
+	}
+	if !f.Allowed("10.1.2.3") {
+		t.Fatal("failed Update replaced the rules")
+	}
+	if err := f.Update(&IPFilterConfig{Allow: []string{"192.0.2.0/24"}}); err != nil {
+		t.Fatal(err)
+	}
+	if f.Allowed("10.1.2.3") || !f.Allowed("192.0.2.1") {
+		t.Fatal("Update did not swap the rules")
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsConfig configures HTTP metrics
+type MetricsConfig struct {
+	Namespace string // metric name prefix, e.g. "orders"
+	// Registry defaults to a new registry with Go runtime and process collectors
+	Registry *prometheus.Registry
+	// Buckets are latency histogram buckets in seconds
+	Buckets []float64
+	// SizeBuckets are response size histogram buckets in bytes
+	SizeBuckets []float64
+	// SkipPaths are not recorded, e.g. "/metrics" and health checks.
+	// Patterns ending in "*" match by prefix.
+	SkipPaths []string
+}
+
+// DefaultMetricsConfig returns default buckets and skips the metrics and health endpoints
+func DefaultMetricsConfig() *MetricsConfig {
+	return &MetricsConfig{
+		Buckets:     prometheus.DefBuckets,
+		SizeBuckets: prometheus.ExponentialBuckets(100, 10, 6), // 100 B .. 10 MB
+		SkipPaths:   []string{"/metrics", "/healthz", "/livez", "/readyz"},
+	}
+}
+
+// Metrics records request count, latency, in-flight requests and response size.
+// Labels are bounded: routes use the template from c.FullPath() ("unmatched"
+// for 404s), methods outside the standard set become "OTHER", statuses are
+// grouped into classes and codes come from the constants package.
+type Metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	skip         *pathMatcher
+}
+
+// NewMetrics creates and registers the HTTP metrics
+func NewMetrics(cfg *MetricsConfig) *Metrics {
+	if cfg == nil {
+		cfg = DefaultMetricsConfig()
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = prometheus.NewRegistry()
+		cfg.Registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = prometheus.DefBuckets
+	}
+	if len(cfg.SizeBuckets) == 0 {
+		cfg.SizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
+	}
+
+	labels := []string{"method", "route", "status_class", "code"}
+	m := &Metrics{
+		registry: cfg.Registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency in seconds.",
+			Buckets:   cfg.Buckets,
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "http",
+			Name:      "response_size_bytes",
+			Help:      "HTTP response body size in bytes.",
+			Buckets:   cfg.SizeBuckets,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests currently being served.",
+		}, []string{"method"}),
+		skip: newPathMatcher(cfg.SkipPaths),
+	}
+
+	cfg.Registry.MustRegister(m.requests, m.duration, m.responseSize, m.inFlight)
+	return m
+}
+
+// Registry returns the registry metrics are recorded in, to register custom collectors
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Middleware records metrics for every request
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.skip.match(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		method := normalizeMethod(c.Request.Method)
+
+		inFlight := m.inFlight.WithLabelValues(method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status, code := responseStatus(c)
+		labels := prometheus.Labels{
+			"method":       method,
+			"route":        route,
+			"status_class": strconv.Itoa(status/100) + "xx",
+			"code":         strconv.Itoa(code),
+		}
+
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+		m.responseSize.With(labels).Observe(float64(max(c.Writer.Size(), 0)))
+	}
+}
+
+// Handler serves metrics, negotiating OpenMetrics text format with scrapers that ask for it
+//
+//	r.GET("/metrics", metrics.Handler())
+func (m *Metrics) Handler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+		Registry:          m.registry,
+	})
+	return gin.WrapH(h)
+}
+
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
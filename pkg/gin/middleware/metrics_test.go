@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsSkipPathsMatchPrefixes(t *testing.T) {
+	m := NewMetrics(&MetricsConfig{Registry: prometheus.NewRegistry(), SkipPaths: []string{"/metrics", "/internal/*"}})
+	r := newTestRouter(m.Middleware())
+	r.GET("/metrics", ok)
+	r.GET("/internal/status", ok)
+	r.GET("/orders/:id", ok)
+
+	serve(r, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	serve(r, httptest.NewRequest(http.MethodGet, "/internal/status", nil))
+	serve(r, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	serve(r, httptest.NewRequest(http.MethodGet, "/orders/2", nil))
+
+	if n := testutil.CollectAndCount(m.requests); n != 1 {
+		t.Fatalf("recorded %d series, want only the orders route", n)
+	}
+	if v := testutil.ToFloat64(m.requests.WithLabelValues("GET", "/orders/:id", "2xx", "0")); v != 2 {
+		t.Fatalf("orders requests = %v, want 2", v)
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+	"github.com/nhstop/go-utils/pkg/logger"
+	"github.com/nhstop/go-utils/pkg/ratelimit"
+)
+
+// RateLimitKeyFunc returns the key a request is counted against.
+// Returning an empty key skips rate limiting for the request.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitConfig configures the RateLimit middleware
+type RateLimitConfig struct {
+	Store      ratelimit.Store
+	Limit      ratelimit.Limit
+	KeyFunc    RateLimitKeyFunc
+	Prefix     string // namespaces keys, e.g. "otp" so routes don't share a budget
+	FailClosed bool   // respond 503 when the store errors instead of letting requests through
+}
+
+// DefaultRateLimitConfig limits each client IP to 60 requests per minute in memory
+func DefaultRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Store:   ratelimit.NewMemoryStore(time.Minute),
+		Limit:   ratelimit.PerMinute(60),
+		KeyFunc: KeyByIP(),
+	}
+}
+
+// KeyByIP keys requests by client IP, as resolved by RealIP when registered
+func KeyByIP() RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		return "ip:" + ClientIP(c)
+	}
+}
+
+// KeyBySubject keys requests by authenticated subject, falling back to client IP
+func KeyBySubject() RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if sub := c.GetString(SubjectKey); sub != "" {
+			return "sub:" + sub
+		}
+		return "ip:" + ClientIP(c)
+	}
+}
+
+// KeyByAPIKey keys requests by the value of header (e.g. "X-API-Key").
+// Keys are hashed so raw secrets never reach the store.
+func KeyByAPIKey(header string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if key := c.GetHeader(header); key != "" {
+			sum := sha256.Sum256([]byte(key))
+			return "key:" + hex.EncodeToString(sum[:])
+		}
+		return ""
+	}
+}
+
+// RateLimit rejects requests exceeding cfg.Limit with 429 and sets RateLimit-* headers.
+// It panics on a missing Store or a non-positive Limit, which would otherwise
+// let every request through.
+func RateLimit(cfg *RateLimitConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultRateLimitConfig()
+	}
+	if cfg.Store == nil {
+		panic("middleware: RateLimitConfig.Store is required")
+	}
+	if cfg.Limit.Requests <= 0 || cfg.Limit.Period <= 0 {
+		panic("middleware: RateLimitConfig.Limit must allow at least one request per positive period")
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = KeyByIP()
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", cfg.Limit.Requests, int(math.Ceil(cfg.Limit.Period.Seconds())))
+
+	return func(c *gin.Context) {
+		key := cfg.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if cfg.Prefix != "" {
+			key = cfg.Prefix + ":" + key
+		}
+
+		res, err := cfg.Store.Take(c.Request.Context(), key, cfg.Limit)
+		if err != nil {
+			logger.Error("rate limit store error: %v", err)
+			if cfg.FailClosed {
+				c.Error(apperr.NewError(apperr.ErrorParams{
+					HTTPCode: http.StatusServiceUnavailable,
+					Code:     constants.InternalServer,
+					Message:  "rate limiter unavailable",
+					Err:      err,
+				}))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			c.Error(apperr.NewError(apperr.ErrorParams{
+				HTTPCode: http.StatusTooManyRequests,
+				Code:     constants.TooManyRequests,
+				Message:  "too many requests, please try again later",
+			}))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nhstop/go-utils/pkg/constants"
+	"github.com/nhstop/go-utils/pkg/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("redis: connection refused")
+}
+
+func TestRateLimitRejectsOverLimit(t *testing.T) {
+	r := newTestRouter(RateLimit(&RateLimitConfig{
+		Store: ratelimit.NewMemoryStore(time.Minute),
+		Limit: ratelimit.Limit{Requests: 2, Period: time.Minute},
+	}))
+	r.GET("/", ok)
+
+	for i := 0; i < 2; i++ {
+		w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+		assertStatus(t, w, http.StatusOK)
+	}
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertStatus(t, w, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("missing Retry-After")
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Fatalf("RateLimit-Remaining = %q", got)
+	}
+}
+
+func TestRateLimitValidatesConfig(t *testing.T) {
+	store := ratelimit.NewMemoryStore(time.Minute)
+	assertPanics(t, "nil store", func() {
+		RateLimit(&RateLimitConfig{Limit: ratelimit.PerMinute(1)})
+	})
+	assertPanics(t, "zero requests", func() {
+		RateLimit(&RateLimitConfig{Store: store, Limit: ratelimit.PerMinute(0)})
+	})
+	assertPanics(t, "zero window", func() {
+		RateLimit(&RateLimitConfig{Store: store, Limit: ratelimit.Limit{Requests: 1}})
+	})
+}
+
+func TestRateLimitStoreErrors(t *testing.T) {
+	cfg := &RateLimitConfig{Store: failingStore{}, Limit: ratelimit.PerMinute(1)}
+	r := newTestRouter(RateLimit(cfg))
+	r.GET("/", ok)
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, "/", nil)), http.StatusOK)
+
+	cfg = &RateLimitConfig{Store: failingStore{}, Limit: ratelimit.PerMinute(1), FailClosed: true}
+	r = newTestRouter(RateLimit(cfg))
+	r.GET("/", ok)
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertStatus(t, w, http.StatusServiceUnavailable)
+
+	var body struct {
+		Code int `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != constants.InternalServer {
+		t.Fatalf("code = %d, want %d", body.Code, constants.InternalServer)
+	}
+}
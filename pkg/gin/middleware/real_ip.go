@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Forwarding headers understood by RealIP
+const (
+	HeaderForwarded     = "Forwarded"
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+)
+
+// RealIPConfig configures the RealIP middleware
+type RealIPConfig struct {
+	// TrustedProxies lists CIDRs or single IPs of proxies allowed to set
+	// forwarding headers, e.g. the VPC range the load balancer lives in
+	TrustedProxies []string
+	// Headers are tried in order, the first one present wins.
+	// Defaults to Forwarded, X-Forwarded-For, X-Real-IP.
+	Headers []string
+	// AllowedHosts lists hosts a trusted proxy may report in Forwarded host=
+	// or X-Forwarded-Host, e.g. "api.example.com". Load balancers such as ALBs
+	// pass client-supplied values through, so any other value is ignored and
+	// RequestHost stays the request's Host. Empty ignores forwarded hosts.
+	AllowedHosts []string
+}
+
+// DefaultRealIPConfig trusts proxies on private networks, which covers load
+// balancers inside a VPC
+func DefaultRealIPConfig() *RealIPConfig {
+	return &RealIPConfig{
+		TrustedProxies: PrivateNetworks(),
+		Headers:        []string{HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP},
+	}
+}
+
+// PrivateNetworks returns loopback, RFC 1918 and IPv6 unique local ranges
+func PrivateNetworks() []string {
+	return []string{"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "::1/128", "fc00::/7"}
+}
+
+// requestOrigin is what RealIP resolved for a request
+type requestOrigin struct {
+	clientIP string
+	scheme   string
+	host     string
+}
+
+// RealIP resolves the client IP, scheme and host of requests arriving through
+// proxies. Forwarding headers are only believed when the connecting peer is a
+// trusted proxy; the client is the rightmost address in the chain that is not
+// itself a trusted proxy, so values prepended by the client are ignored.
+// Scheme comes from Forwarded proto or X-Forwarded-Proto, and so does the
+// host from Forwarded host or X-Forwarded-Host when listed in AllowedHosts.
+//
+// Register it first, and read the results with ClientIP, RequestScheme,
+// RequestHost and AbsoluteURL.
+func RealIP(cfg *RealIPConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultRealIPConfig()
+	}
+	if len(cfg.Headers) == 0 {
+		cfg.Headers = DefaultRealIPConfig().Headers
+	}
+
+	trusted, err := parsePrefixes(cfg.TrustedProxies)
+	if err != nil {
+		panic("middleware: RealIPConfig.TrustedProxies: " + err.Error())
+	}
+	allowedHosts := make(map[string]bool)
+	for _, h := range cfg.AllowedHosts {
+		allowedHosts[strings.ToLower(h)] = true
+	}
+	isTrusted := func(addr netip.Addr) bool {
+		for _, p := range trusted {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *gin.Context) {
+		r := c.Request
+		origin := &requestOrigin{scheme: "http", host: r.Host}
+		if r.TLS != nil {
+			origin.scheme = "https"
+		}
+
+		peer, ok := parseAddr(r.RemoteAddr)
+		if ok {
+			origin.clientIP = peer.String()
+		}
+
+		if ok && isTrusted(peer) {
+			for _, h := range cfg.Headers {
+				values := r.Header.Values(h)
+				if len(values) == 0 {
+					continue
+				}
+				if ip, ok := clientFromChain(forwardedChain(h, values), isTrusted); ok {
+					origin.clientIP = ip.String()
+				}
+				break
+			}
+
+			if proto, host := forwardedProtoHost(r); proto != "" || host != "" {
+				if proto == "http" || proto == "https" {
+					origin.scheme = proto
+				}
+				if host != "" && allowedHosts[strings.ToLower(host)] {
+					origin.host = host
+				}
+			}
+		}
+
+		c.Set(requestOriginKey, origin)
+		c.Next()
+	}
+}
+
+// ClientIP returns the client IP resolved by RealIP. Without RealIP it is the
+// connecting peer, c.RemoteIP(), since gin's c.ClientIP() believes
+// X-Forwarded-For from any peer unless the engine's trusted proxies are set.
+func ClientIP(c *gin.Context) string {
+	if o := getRequestOrigin(c); o != nil && o.clientIP != "" {
+		return o.clientIP
+	}
+	return c.RemoteIP()
+}
+
+// RequestScheme returns "https" or "http" as seen by the client
+func RequestScheme(c *gin.Context) string {
+	if o := getRequestOrigin(c); o != nil {
+		return o.scheme
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// RequestHost returns the host, with any port, the client sent the request to
+func RequestHost(c *gin.Context) string {
+	if o := getRequestOrigin(c); o != nil {
+		return o.host
+	}
+	return c.Request.Host
+}
+
+// AbsoluteURL resolves ref (e.g. "/orders/42") against the URL the client requested
+func AbsoluteURL(c *gin.Context, ref string) string {
+	base := &url.URL{Scheme: RequestScheme(c), Host: RequestHost(c), Path: c.Request.URL.Path}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return base.String()
+	}
+	return base.ResolveReference(u).String()
+}
+
+func getRequestOrigin(c *gin.Context) *requestOrigin {
+	v, _ := c.Get(requestOriginKey)
+	o, _ := v.(*requestOrigin)
+	return o
+}
+
+// forwardedChain returns the addresses in a forwarding header, client first
+func forwardedChain(header string, values []string) []string {
+	var chain []string
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			element = strings.TrimSpace(element)
+			if !strings.EqualFold(header, HeaderForwarded) {
+				chain = append(chain, element)
+				continue
+			}
+			// Forwarded: for=192.0.2.60;proto=https;by=203.0.113.43
+			chain = append(chain, forwardedParam(element, "for"))
+		}
+	}
+	return chain
+}
+
+// clientFromChain walks the chain right to left past trusted proxies
+func clientFromChain(chain []string, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var last netip.Addr
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(chain[i])
+		if !ok {
+			// Obfuscated or malformed hop, nothing to its left can be trusted
+			break
+		}
+		last = addr
+		if !isTrusted(addr) {
+			return addr, true
+		}
+	}
+	// Every hop reached is a trusted proxy, use the one furthest from us
+	return last, last.IsValid()
+}
+
+// forwardedProtoHost returns the scheme and host set by the nearest proxy
+func forwardedProtoHost(r *http.Request) (string, string) {
+	if values := r.Header.Values(HeaderForwarded); len(values) > 0 {
+		elements := strings.Split(values[len(values)-1], ",")
+		last := strings.TrimSpace(elements[len(elements)-1])
+		return strings.ToLower(forwardedParam(last, "proto")), forwardedParam(last, "host")
+	}
+	return strings.ToLower(lastListValue(r.Header.Get("X-Forwarded-Proto"))), lastListValue(r.Header.Get("X-Forwarded-Host"))
+}
+
+// forwardedParam returns one parameter of a Forwarded element, unquoted
+func forwardedParam(element, name string) string {
+	for _, pair := range strings.Split(element, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(k, name) {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+func lastListValue(v string) string {
+	if i := strings.LastIndex(v, ","); i >= 0 {
+		v = v[i+1:]
+	}
+	return strings.TrimSpace(v)
+}
+
+// parseAddr parses "1.2.3.4", "1.2.3.4:80", "[::1]:80" or "::1"
+func parseAddr(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		return p.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
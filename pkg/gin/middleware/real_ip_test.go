@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func originRouter(cfg *RealIPConfig) *gin.Engine {
+	return newTestRouter(RealIP(cfg), func(c *gin.Context) {
+		c.String(http.StatusOK, ClientIP(c)+" "+AbsoluteURL(c, "/next"))
+	})
+}
+
+func originRequest(peer string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "api.example.com"
+	req.RemoteAddr = peer + ":4321"
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestRealIPIgnoresHeadersFromUntrustedPeers(t *testing.T) {
+	r := originRouter(nil)
+	w := serve(r, originRequest("203.0.113.9", map[string]string{
+		"X-Forwarded-For":   "10.1.2.3",
+		"X-Forwarded-Proto": "https",
+		"X-Forwarded-Host":  "evil.example",
+	}))
+	assertStatus(t, w, http.StatusOK)
+	if got, want := w.Body.String(), "203.0.113.9 http://api.example.com/next"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+}
+
+func TestRealIPSkipsClientPrependedAddresses(t *testing.T) {
+	r := originRouter(nil)
+	w := serve(r, originRequest("10.0.0.5", map[string]string{
+		"X-Forwarded-For":   "1.2.3.4, 198.51.100.7, 10.0.0.6",
+		"X-Forwarded-Proto": "https",
+	}))
+	if got, want := w.Body.String(), "198.51.100.7 https://api.example.com/next"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+}
+
+func TestRealIPForwardedHeader(t *testing.T) {
+	r := originRouter(nil)
+	w := serve(r, originRequest("10.0.0.5", map[string]string{
+		"Forwarded": `for=1.2.3.4, for="[2001:db8::1]:443";proto=https`,
+	}))
+	if got, want := w.Body.String(), "2001:db8::1 https://api.example.com/next"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+}
+
+func TestRealIPForwardedHostRequiresAllowList(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		want    string
+	}{
+		{"not configured", nil, "evil.example", "https://api.example.com/next"},
+		{"not allowed", []string{"www.example.com"}, "evil.example", "https://api.example.com/next"},
+		{"allowed", []string{"WWW.example.com"}, "www.example.com", "https://www.example.com/next"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultRealIPConfig()
+			cfg.AllowedHosts = tt.allowed
+			r := newTestRouter(RealIP(cfg), func(c *gin.Context) {
+				c.String(http.StatusOK, AbsoluteURL(c, "/next"))
+			})
+			w := serve(r, originRequest("10.0.0.5", map[string]string{
+				"X-Forwarded-Proto": "https",
+				"X-Forwarded-Host":  tt.host,
+			}))
+			if got := w.Body.String(); got != tt.want {
+				t.Fatalf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPPanicsOnInvalidProxy(t *testing.T) {
+	assertPanics(t, "invalid proxy", func() {
+		RealIP(&RealIPConfig{TrustedProxies: []string{"not-an-ip"}})
+	})
+}
@@ -32,7 +32,7 @@ func RequestLogger() gin.HandlerFunc {
 		}
 
 		// Log the request using your custom logger
-		logger.Info("%s %s | %s%d%s | %v", method, path, statusColor, status, constants.ColorReset, latency)
+		logger.Info("%s %s | %s%d%s | %v | %s", method, path, statusColor, status, constants.ColorReset, latency, ClientIP(c))
 
 	}
 }
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errBufferedHijack = errors.New("middleware: hijacking is not supported on buffered responses")
+
+// bufferedWriter holds the response in memory until flush is called, so a
+// middleware can inspect, replace or drop it after the handler returns.
+// Streaming responses are delayed until the handler finishes.
+type bufferedWriter struct {
+	gin.ResponseWriter // underlying writer, written to by flush
+
+	mu        sync.Mutex
+	header    http.Header
+	body      bytes.Buffer
+	status    int
+	wroteHead bool
+	closed    bool // set once flushed or discarded, later writes fail
+}
+
+func newBufferedWriter(w gin.ResponseWriter) *bufferedWriter {
+	header := make(http.Header, len(w.Header()))
+	for k, v := range w.Header() {
+		header[k] = append([]string(nil), v...)
+	}
+	return &bufferedWriter{
+		ResponseWriter: w,
+		header:         header,
+		status:         http.StatusOK,
+	}
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.wroteHead {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wroteHead = true
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.wroteHead = true
+	return w.body.Write(p)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.wroteHead {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.wroteHead
+}
+
+// Flush is a no-op, the body is only sent by flush
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errBufferedHijack
+}
+
+func (w *bufferedWriter) Pusher() http.Pusher {
+	return nil
+}
+
+// bytes returns the buffered body
+func (w *bufferedWriter) bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.body.Bytes()
+}
+
+// replace swaps the buffered status and body, keeping the headers
+func (w *bufferedWriter) replace(status int, body []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status = status
+	w.wroteHead = true
+	w.body.Reset()
+	w.body.Write(body)
+}
+
+// discard drops the buffered response, later writes from the handler fail
+func (w *bufferedWriter) discard() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	w.body.Reset()
+}
+
+// flush copies headers, status and body to the underlying writer
+func (w *bufferedWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+
+	dst := w.ResponseWriter.Header()
+	for k := range dst {
+		if _, ok := w.header[k]; !ok {
+			dst.Del(k)
+		}
+	}
+	for k, v := range w.header {
+		dst[k] = v
+	}
+
+	// gin only records the status here, it is sent with the body or by WriteHeaderNow
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	} else if w.wroteHead {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -1,89 +1,257 @@
 package middleware
 
 import (
-	"strings"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/logger"
 )
 
-// SecurityHeadersConfig allows customization of headers and CORS
+// HSTSConfig configures Strict-Transport-Security
+type HSTSConfig struct {
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	Preload           bool
+}
+
+func (h *HSTSConfig) String() string {
+	v := fmt.Sprintf("max-age=%d", int(h.MaxAge.Seconds()))
+	if h.IncludeSubDomains {
+		v += "; includeSubDomains"
+	}
+	if h.Preload {
+		v += "; preload"
+	}
+	return v
+}
+
+// SecurityHeadersConfig configures each security header as its own policy.
+// Empty values (or nil pointers) omit the header, so start from a preset and
+// adjust what a route group needs:
+//
+//	api := r.Group("/api", middleware.SecurityHeaders(middleware.APISecurityConfig()))
+//	docs := r.Group("/docs", middleware.SecurityHeaders(middleware.DocsSecurityConfig()))
 type SecurityHeadersConfig struct {
-	AllowedOrigins []string // e.g. []string{"http://localhost:3000", "https://example.com"}
-	AllowedMethods []string // e.g. GET, POST, PUT, DELETE
-	AllowedHeaders []string // e.g. Origin, Content-Type, Authorization
+	ContentTypeOptions        string // X-Content-Type-Options, e.g. "nosniff"
+	FrameOptions              string // X-Frame-Options, e.g. "DENY" or "SAMEORIGIN"
+	ReferrerPolicy            string // e.g. "no-referrer", "strict-origin-when-cross-origin"
+	CSP                       *CSP   // Content-Security-Policy, nonces are generated when it contains CSPNonce
+	CSPReportOnly             bool   // send CSP as Content-Security-Policy-Report-Only
+	HSTS                      *HSTSConfig
+	PermissionsPolicy         string // e.g. "camera=(), microphone=(), geolocation=()"
+	CrossOriginOpenerPolicy   string // e.g. "same-origin"
+	CrossOriginEmbedderPolicy string // e.g. "require-corp", breaks pages embedding third-party assets
+	CrossOriginResourcePolicy string // e.g. "same-origin"
+	CacheControl              string // e.g. "no-store" for sensitive responses, empty for cacheable ones
+	KeepServerHeader          bool   // keep the Server header instead of removing it
+
+	// Deprecated headers, omitted by presets. Browsers have removed support for
+	// both; X-XSS-Protection can even introduce vulnerabilities unless set to "0".
+	XSSProtection string
+	ExpectCT      string
+
+	// Deprecated: register the CORS middleware instead. When AllowedOrigins is
+	// set, SecurityHeaders applies CORS with these values for compatibility.
+	AllowedOrigins []string
+	// Deprecated: use CORSConfig.AllowedMethods
+	AllowedMethods []string
+	// Deprecated: use CORSConfig.AllowedHeaders
+	AllowedHeaders []string
 }
 
-// DefaultConfig returns a safe default configuration
+// DefaultConfig returns the headers SecurityHeaders has always sent, including
+// a default-src 'self' CSP that lets pages load their own scripts and styles,
+// and CORS for any origin. New code should pick a preset such as
+// APISecurityConfig and register CORS separately.
 func DefaultConfig() *SecurityHeadersConfig {
 	return &SecurityHeadersConfig{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Origin", "Content-Type", "Authorization"},
+		ContentTypeOptions: "nosniff",
+		FrameOptions:       "DENY",
+		ReferrerPolicy:     "no-referrer",
+		CSP: NewCSP().
+			DefaultSrc(CSPSelf).
+			ImgSrc(CSPSelf, CSPData).
+			ScriptSrc(CSPSelf).
+			StyleSrc(CSPSelf, CSPUnsafeInline).
+			FontSrc(CSPSelf).
+			ConnectSrc(CSPSelf),
+		HSTS:                      &HSTSConfig{MaxAge: 2 * 365 * 24 * time.Hour, IncludeSubDomains: true, Preload: true},
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginEmbedderPolicy: "require-corp",
+		CacheControl:              "no-store, no-cache, must-revalidate, private",
+		XSSProtection:             "1; mode=block",
+		ExpectCT:                  "max-age=86400, enforce",
+		AllowedOrigins:            []string{"*"},
+		AllowedMethods:            []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:            []string{"Origin", "Content-Type", "Authorization"},
+	}
+}
+
+// APISecurityConfig is a preset for JSON APIs: nothing may be rendered,
+// framed or cached
+func APISecurityConfig() *SecurityHeadersConfig {
+	return &SecurityHeadersConfig{
+		ContentTypeOptions:        "nosniff",
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "no-referrer",
+		CSP:                       NewCSP().DefaultSrc(CSPNone).FrameAncestors(CSPNone),
+		HSTS:                      defaultHSTS(),
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginResourcePolicy: "same-origin",
+		CacheControl:              "no-store",
 	}
 }
 
-// Features included
-// Security headers: X-Content-Type-Options, X-Frame-Options, X-XSS-Protection
-// Referrer policy: Protect privacy
-// CSP: Prevents XSS and injection
-// HSTS: Enforces HTTPS
-// COOP + COEP: Cross-origin isolation
-// Cache-Control: Protect sensitive pages
-// Expect-CT: Certificate transparency enforcement
-// CORS: Configurable origins, methods, headers, and preflight handling
-// Remove Server header: Reduces fingerprinting
-// Optional secure cookie example
-
-// SecurityHeaders middleware adds security headers + CORS + removes Server header
+// HTMLSecurityConfig is a preset for server-rendered pages. Scripts and styles
+// must carry the per-request nonce from GetCSPNonce.
+func HTMLSecurityConfig() *SecurityHeadersConfig {
+	return &SecurityHeadersConfig{
+		ContentTypeOptions: "nosniff",
+		FrameOptions:       "DENY",
+		ReferrerPolicy:     "strict-origin-when-cross-origin",
+		CSP: NewCSP().
+			DefaultSrc(CSPSelf).
+			ScriptSrc(CSPSelf, CSPNonce).
+			StyleSrc(CSPSelf, CSPNonce).
+			ImgSrc(CSPSelf, CSPData).
+			FontSrc(CSPSelf).
+			ConnectSrc(CSPSelf).
+			ObjectSrc(CSPNone).
+			BaseURI(CSPSelf).
+			FormAction(CSPSelf).
+			FrameAncestors(CSPNone),
+		HSTS:                    defaultHSTS(),
+		PermissionsPolicy:       "camera=(), microphone=(), geolocation=()",
+		CrossOriginOpenerPolicy: "same-origin",
+		CacheControl:            "no-cache",
+	}
+}
+
+// DocsSecurityConfig is a preset for Swagger UI / Redoc style pages, which rely
+// on inline scripts and styles and may be cached
+func DocsSecurityConfig() *SecurityHeadersConfig {
+	return &SecurityHeadersConfig{
+		ContentTypeOptions: "nosniff",
+		FrameOptions:       "SAMEORIGIN",
+		ReferrerPolicy:     "strict-origin-when-cross-origin",
+		CSP: NewCSP().
+			DefaultSrc(CSPSelf).
+			ScriptSrc(CSPSelf, CSPUnsafeInline).
+			StyleSrc(CSPSelf, CSPUnsafeInline).
+			ImgSrc(CSPSelf, CSPData, CSPHTTPS).
+			FontSrc(CSPSelf, CSPData).
+			ConnectSrc(CSPSelf).
+			ObjectSrc(CSPNone).
+			FrameAncestors(CSPSelf),
+		HSTS: defaultHSTS(),
+	}
+}
+
+func defaultHSTS() *HSTSConfig {
+	return &HSTSConfig{MaxAge: 2 * 365 * 24 * time.Hour, IncludeSubDomains: true}
+}
+
+// GetCSPNonce returns the CSP nonce generated for this request, for use in templates:
+//
+//	c.HTML(200, "index.html", gin.H{"nonce": middleware.GetCSPNonce(c)})
+//	<script nonce="{{ .nonce }}">...</script>
+func GetCSPNonce(c *gin.Context) string {
+	return c.GetString(CSPNonceKey)
+}
+
+// legacyCORSConfig converts the deprecated CORS fields. Credentials stay enabled
+// for explicit origins as before, but never for "*" which would reflect any origin.
+func legacyCORSConfig(cfg *SecurityHeadersConfig) *CORSConfig {
+	corsCfg := DefaultCORSConfig()
+	corsCfg.AllowedOrigins = cfg.AllowedOrigins
+	corsCfg.AllowCredentials = !slices.Contains(cfg.AllowedOrigins, "*")
+	if len(cfg.AllowedMethods) > 0 {
+		corsCfg.AllowedMethods = cfg.AllowedMethods
+	}
+	if len(cfg.AllowedHeaders) > 0 {
+		corsCfg.AllowedHeaders = cfg.AllowedHeaders
+	}
+	return corsCfg
+}
+
+// SecurityHeaders middleware adds the configured security headers + removes Server header
 func SecurityHeaders(cfg *SecurityHeadersConfig) gin.HandlerFunc {
 	if cfg == nil {
 		cfg = DefaultConfig()
 	}
 
+	// Everything except a nonce-based CSP is the same for every request
+	static := map[string]string{
+		"X-Content-Type-Options":       cfg.ContentTypeOptions,
+		"X-Frame-Options":              cfg.FrameOptions,
+		"Referrer-Policy":              cfg.ReferrerPolicy,
+		"Permissions-Policy":           cfg.PermissionsPolicy,
+		"Cross-Origin-Opener-Policy":   cfg.CrossOriginOpenerPolicy,
+		"Cross-Origin-Embedder-Policy": cfg.CrossOriginEmbedderPolicy,
+		"Cross-Origin-Resource-Policy": cfg.CrossOriginResourcePolicy,
+		"Cache-Control":                cfg.CacheControl,
+		"X-XSS-Protection":             cfg.XSSProtection,
+		"Expect-CT":                    cfg.ExpectCT,
+	}
+	if cfg.HSTS != nil {
+		static["Strict-Transport-Security"] = cfg.HSTS.String()
+	}
+
+	cspHeader := "Content-Security-Policy"
+	if cfg.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	useNonce := cfg.CSP != nil && cfg.CSP.UsesNonce()
+	if cfg.CSP != nil && !useNonce {
+		static[cspHeader] = cfg.CSP.String()
+	}
+
+	var cors gin.HandlerFunc
+	if len(cfg.AllowedOrigins) > 0 {
+		cors = CORS(legacyCORSConfig(cfg))
+	}
+
 	return func(c *gin.Context) {
 		// ---------------- Security Headers ----------------
-		c.Header("X-Content-Type-Options", "nosniff")
-		c.Header("X-Frame-Options", "DENY")
-		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Referrer-Policy", "no-referrer")
-		c.Header("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; script-src 'self'; style-src 'self' 'unsafe-inline'; font-src 'self'; connect-src 'self'")
-		c.Header("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
-		c.Header("Cross-Origin-Opener-Policy", "same-origin")
-		c.Header("Cross-Origin-Embedder-Policy", "require-corp")
-		c.Header("Cache-Control", "no-store, no-cache, must-revalidate, private")
-		c.Header("Expect-CT", "max-age=86400, enforce")
-
-		// ---------------- CORS ----------------
-		origin := c.GetHeader("Origin")
-		for _, o := range cfg.AllowedOrigins {
-			if o == "*" || o == origin {
-				c.Header("Access-Control-Allow-Origin", o)
-				c.Header("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
-				c.Header("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
-				c.Header("Access-Control-Allow-Credentials", "true")
-				break
+		for name, value := range static {
+			if value != "" {
+				c.Header(name, value)
 			}
 		}
 
-		// Handle preflight OPTIONS request
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+		// ---------------- CSP nonce ----------------
+		if useNonce {
+			nonce, err := newCSPNonce()
+			if err != nil {
+				logger.Error("failed to generate CSP nonce: %v", err)
+			}
+			c.Set(CSPNonceKey, nonce)
+			c.Header(cspHeader, cfg.CSP.Build(nonce))
 		}
 
 		// ---------------- Remove Server header ----------------
-		c.Writer.Header().Del("Server")
-
-		// ---------------- Optional: Secure cookies example ----------------
-		// Uncomment and use when setting cookies:
-		// http.SetCookie(c.Writer, &http.Cookie{
-		//     Name:     "session",
-		//     Value:    "xyz",
-		//     HttpOnly: true,
-		//     Secure:   true,
-		//     SameSite: http.SameSiteStrictMode,
-		// })
+		if !cfg.KeepServerHeader {
+			c.Writer.Header().Del("Server")
+		}
+
+		// ---------------- Deprecated CORS ----------------
+		if cors != nil {
+			cors(c)
+			return
+		}
 
 		c.Next()
 	}
 }
+
+func newCSPNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultConfigKeepsLegacyCORS(t *testing.T) {
+	r := newTestRouter(SecurityHeaders(DefaultConfig()))
+	r.GET("/", ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := serve(r, req)
+	assertStatus(t, w, http.StatusOK)
+	if w.Header().Get("Access-Control-Allow-Origin") == "" {
+		t.Fatal("DefaultConfig no longer allows cross-origin requests")
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatal("missing security headers")
+	}
+}
+
+func TestDefaultConfigKeepsLegacyHeaders(t *testing.T) {
+	r := newTestRouter(SecurityHeaders(nil))
+	r.GET("/", ok)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	want := map[string]string{
+		"Content-Security-Policy":      "default-src 'self'; img-src 'self' data:; script-src 'self'; style-src 'self' 'unsafe-inline'; font-src 'self'; connect-src 'self'",
+		"Strict-Transport-Security":    "max-age=63072000; includeSubDomains; preload",
+		"X-Frame-Options":              "DENY",
+		"Referrer-Policy":              "no-referrer",
+		"Cross-Origin-Embedder-Policy": "require-corp",
+		"Cache-Control":                "no-store, no-cache, must-revalidate, private",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestAPISecurityConfigHasNoCORS(t *testing.T) {
+	r := newTestRouter(SecurityHeaders(APISecurityConfig()))
+	r.GET("/", ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := serve(r, req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("APISecurityConfig should not answer CORS")
+	}
+}
+
+func TestCORSPreflightRejectsUnknownOrigin(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	cfg.AllowedOrigins = []string{"https://app.example.com"}
+	r := newTestRouter(CORS(cfg))
+	r.POST("/", ok)
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	assertStatus(t, serve(r, req), http.StatusForbidden)
+
+	req.Header.Set("Origin", "https://app.example.com")
+	w := serve(r, req)
+	assertStatus(t, w, http.StatusNoContent)
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	"github.com/nhstop/go-utils/pkg/logger"
+	"github.com/nhstop/go-utils/pkg/session"
+)
+
+// SessionConfig configures the Session middleware
+type SessionConfig struct {
+	Store session.Store // e.g. session.NewCookieStore(key) or session.NewPostgresStore(pool, "")
+
+	CookieName   string        // defaults to "session"
+	CookiePath   string        // defaults to "/"
+	CookieDomain string        // empty scopes the cookie to the current host
+	SameSite     http.SameSite // defaults to http.SameSiteLaxMode
+	// InsecureCookie drops the Secure flag, for local development over plain HTTP
+	InsecureCookie bool
+
+	MaxAge time.Duration // lifetime since the last save, defaults to 24h
+	// Rolling saves the session on every request so MaxAge counts from the
+	// last request instead of the last change
+	Rolling bool
+	// AbsoluteTimeout ends sessions this long after they were created or
+	// regenerated, however active they are. 0 disables it.
+	AbsoluteTimeout time.Duration
+}
+
+// Session loads the caller's session, see GetSession. Sessions are saved and
+// the cookie set just before the response is written, and only when they were
+// changed (or on every request with Rolling), so anonymous visitors get no cookie.
+// Cookies are always HttpOnly.
+func Session(cfg *SessionConfig) gin.HandlerFunc {
+	if cfg == nil || cfg.Store == nil {
+		panic("middleware: SessionConfig.Store is required")
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "session"
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 24 * time.Hour
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var sess *session.Session
+		if value, err := c.Cookie(cfg.CookieName); err == nil && value != "" {
+			sess, err = cfg.Store.Load(ctx, value)
+			if err != nil && !errors.Is(err, session.ErrNotFound) {
+				abortWithError(c, http.StatusInternalServerError, constants.InternalServer, "failed to load session", err)
+				return
+			}
+		}
+		if sess != nil && cfg.AbsoluteTimeout > 0 && time.Since(sess.CreatedAt) > cfg.AbsoluteTimeout {
+			if err := cfg.Store.Delete(ctx, sess); err != nil {
+				logger.Error("failed to delete expired session: %v", err)
+			}
+			sess = nil
+		}
+		if sess == nil {
+			sess = session.New(cfg.MaxAge)
+		}
+		c.Set(sessionKey, sess)
+
+		committed := false
+		commit := func() {
+			if committed {
+				return
+			}
+			committed = true
+			saveSession(c, cfg, sess)
+		}
+
+		original := c.Writer
+		c.Writer = &sessionWriter{ResponseWriter: original, commit: commit}
+
+		c.Next()
+
+		commit()
+		c.Writer = original
+	}
+}
+
+// GetSession returns the session loaded by Session, or nil
+func GetSession(c *gin.Context) *session.Session {
+	v, _ := c.Get(sessionKey)
+	sess, _ := v.(*session.Session)
+	return sess
+}
+
+// SessionID returns the ID of an existing session, or "" for a session not
+// yet saved. Use it as CSRFConfig.SessionIDFunc to bind CSRF tokens to
+// sessions, registering Session before CSRF.
+func SessionID(c *gin.Context) string {
+	if sess := GetSession(c); sess != nil && !sess.IsNew() {
+		return sess.ID
+	}
+	return ""
+}
+
+// saveSession persists a changed session and sets or expires its cookie
+func saveSession(c *gin.Context, cfg *SessionConfig, sess *session.Session) {
+	ctx := c.Request.Context()
+	cookie := &http.Cookie{
+		Name:     cfg.CookieName,
+		Path:     cfg.CookiePath,
+		Domain:   cfg.CookieDomain,
+		Secure:   !cfg.InsecureCookie,
+		HttpOnly: true,
+		SameSite: cfg.SameSite,
+	}
+
+	if sess.Destroyed() {
+		if !sess.IsNew() {
+			if err := cfg.Store.Delete(ctx, sess); err != nil {
+				logger.Error("failed to delete session: %v", err)
+			}
+			cookie.MaxAge = -1
+			http.SetCookie(c.Writer, cookie)
+		}
+		return
+	}
+	if !sess.Modified() && (!cfg.Rolling || sess.IsNew()) {
+		return
+	}
+
+	sess.ExpiresAt = time.Now().UTC().Add(cfg.MaxAge)
+	if cfg.AbsoluteTimeout > 0 {
+		if limit := sess.CreatedAt.Add(cfg.AbsoluteTimeout); sess.ExpiresAt.After(limit) {
+			sess.ExpiresAt = limit
+		}
+	}
+
+	value, err := cfg.Store.Save(ctx, sess)
+	if err != nil {
+		logger.Error("failed to save session: %v", err)
+		return
+	}
+	cookie.Value = value
+	cookie.Expires = sess.ExpiresAt
+	cookie.MaxAge = int(time.Until(sess.ExpiresAt).Round(time.Second).Seconds())
+	http.SetCookie(c.Writer, cookie)
+}
+
+// sessionWriter saves the session before the first byte of the response,
+// while Set-Cookie can still be added
+type sessionWriter struct {
+	gin.ResponseWriter
+	commit func()
+}
+
+func (w *sessionWriter) Write(p []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Flush() {
+	w.commit()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/session"
+)
+
+func TestSessionCookieStore(t *testing.T) {
+	r := newTestRouter(Session(&SessionConfig{Store: session.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))}))
+	r.POST("/login", func(c *gin.Context) {
+		sess := GetSession(c)
+		sess.Regenerate()
+		sess.Set("user", "alice")
+		ok(c)
+	})
+	r.GET("/me", func(c *gin.Context) {
+		c.String(http.StatusOK, GetSession(c).GetString("user"))
+	})
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/me", nil))
+	if len(w.Result().Cookies()) != 0 {
+		t.Fatal("anonymous visitor got a session cookie")
+	}
+
+	w = serve(r, httptest.NewRequest(http.MethodPost, "/login", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("session cookie = %v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(cookies[0])
+	if w := serve(r, req); w.Body.String() != "alice" {
+		t.Fatalf("user = %q, want alice", w.Body.String())
+	}
+
+	tampered := *cookies[0]
+	tampered.Value = tampered.Value[:len(tampered.Value)-4] + "AAAA"
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(&tampered)
+	if w := serve(r, req); w.Body.String() != "" {
+		t.Fatalf("tampered cookie was accepted: %q", w.Body.String())
+	}
+}
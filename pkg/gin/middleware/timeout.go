@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nhstop/go-utils/pkg/constants"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+)
+
+// TimeoutConfig configures the Timeout middleware
+type TimeoutConfig struct {
+	Timeout time.Duration            // default deadline, 0 disables it
+	Routes  map[string]time.Duration // per route template (c.FullPath()), e.g. "/reports/:id"
+}
+
+// DefaultTimeoutConfig gives every request 10 seconds
+func DefaultTimeoutConfig() *TimeoutConfig {
+	return &TimeoutConfig{Timeout: 10 * time.Second}
+}
+
+// Timeout sets a deadline on the request context so database calls made with
+// c.Request.Context() are cancelled when it passes. It does not interrupt the
+// handler, which runs until it returns and should give up once the context is
+// done. The response is buffered; if the handler overran, whatever it wrote is
+// dropped and ErrorHandler responds with 504 DBTimeout when a database call
+// hit the deadline, or 503 RequestTimeout otherwise.
+//
+// Register it after ErrorHandler. Streaming responses are not supported.
+func Timeout(cfg *TimeoutConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultTimeoutConfig()
+	}
+
+	return func(c *gin.Context) {
+		timeout := cfg.Timeout
+		if d, ok := cfg.Routes[c.FullPath()]; ok {
+			timeout = d
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		buffered := newBufferedWriter(original)
+		c.Writer = buffered
+		done := false
+		defer func() {
+			// Also runs when a handler panics, so Recovery's response reaches the client
+			c.Writer = original
+			if !done {
+				buffered.discard()
+			}
+		}()
+
+		c.Next()
+
+		done = true
+		c.Writer = original
+
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			buffered.flush()
+			return
+		}
+
+		buffered.discard()
+		c.Error(timeoutError(c, timeout))
+		c.Abort()
+	}
+}
+
+// timeoutError reports a database timeout when any error recorded by the
+// handler came from a Postgres deadline or statement timeout
+func timeoutError(c *gin.Context, timeout time.Duration) *apperr.CodedError {
+	for _, e := range c.Errors {
+		var coded *apperr.CodedError
+		if (errors.As(e.Err, &coded) && coded.Code == constants.DBTimeout) || pgconn.Timeout(e.Err) {
+			return apperr.NewError(apperr.ErrorParams{
+				HTTPCode: http.StatusGatewayTimeout,
+				Code:     constants.DBTimeout,
+				Message:  "database operation timed out",
+				Err:      e.Err,
+			})
+		}
+	}
+
+	return apperr.NewError(apperr.ErrorParams{
+		HTTPCode: http.StatusServiceUnavailable,
+		Code:     constants.RequestTimeout,
+		Message:  "request timed out after " + timeout.String(),
+		Err:      context.DeadlineExceeded,
+	})
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/nhstop/go-utils/pkg/constants"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+)
+
+func TestTimeoutRespondsWhenHandlerOverruns(t *testing.T) {
+	r := newTestRouter(Timeout(&TimeoutConfig{Timeout: 20 * time.Millisecond}))
+	r.GET("/", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "late")
+	})
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertStatus(t, w, http.StatusServiceUnavailable)
+}
+
+func TestTimeoutPassesThroughFastResponses(t *testing.T) {
+	r := newTestRouter(Timeout(DefaultTimeoutConfig()))
+	r.GET("/", ok)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertStatus(t, w, http.StatusOK)
+	if w.Body.String() != "ok" {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+}
+
+func TestTimeoutLetsRecoveryRespondToPanics(t *testing.T) {
+	r := gin.New()
+	r.Use(gin.Recovery(), Timeout(DefaultTimeoutConfig()))
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertStatus(t, w, http.StatusInternalServerError)
+	if w.Body.String() == "partial" {
+		t.Fatal("partial response from the panicking handler was sent")
+	}
+}
+
+func TestTimeoutReportsDatabaseTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+	}{
+		{"deadline during a query", func(c *gin.Context) {
+			<-c.Request.Context().Done()
+			c.Error(apperr.PostgresError(c.Request.Context().Err()))
+			c.String(http.StatusOK, "late")
+		}},
+		{"statement timeout", func(c *gin.Context) {
+			c.Error(apperr.PostgresError(&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(Timeout(&TimeoutConfig{Timeout: 20 * time.Millisecond}))
+			r.GET("/", tt.handler)
+
+			w := serve(r, httptest.NewRequest(http.MethodGet, "/", nil))
+			assertStatus(t, w, http.StatusGatewayTimeout)
+			var body struct {
+				Code int `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != constants.DBTimeout {
+				t.Fatalf("code = %d, want %d", body.Code, constants.DBTimeout)
+			}
+		})
+	}
+}
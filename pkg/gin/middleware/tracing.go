@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingConfig configures the Tracing middleware
+type TracingConfig struct {
+	TracerProvider trace.TracerProvider          // defaults to the global provider
+	Propagator     propagation.TextMapPropagator // defaults to tracing.Propagator()
+	SkipPaths      []string                      // exact paths, or prefixes ending in "*"
+	SpanNameFunc   func(c *gin.Context) string   // defaults to "METHOD /route/:template"
+	Filter         func(r *http.Request) bool    // return false to skip tracing a request
+}
+
+// DefaultTracingConfig uses the global provider and skips health checks
+func DefaultTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		SkipPaths: []string{"/healthz", "/livez", "/readyz", "/metrics"},
+	}
+}
+
+// Tracing starts a server span per request, continuing any W3C traceparent
+// sent by the caller. The span context is stored on c.Request.Context(), so
+// database and queue calls made with it become child spans.
+func Tracing(cfg *TracingConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultTracingConfig()
+	}
+
+	tracer := tracing.Tracer(cfg.TracerProvider)
+	skip := newPathMatcher(cfg.SkipPaths)
+
+	return func(c *gin.Context) {
+		if skip.match(c.Request.URL.Path) || (cfg.Filter != nil && !cfg.Filter(c.Request)) {
+			c.Next()
+			return
+		}
+
+		propagator := cfg.Propagator
+		if propagator == nil {
+			propagator = tracing.Propagator()
+		}
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		ctx, span := tracer.Start(ctx, c.Request.Method, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.URLScheme(RequestScheme(c)),
+				semconv.ServerAddress(RequestHost(c)),
+				semconv.ClientAddress(ClientIP(c)),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route != "" {
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		if cfg.SpanNameFunc != nil {
+			span.SetName(cfg.SpanNameFunc(c))
+		} else if route != "" {
+			span.SetName(c.Request.Method + " " + route)
+		}
+
+		status, code := responseStatus(c)
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if code != 0 {
+			span.SetAttributes(attribute.Int("app.error.code", code))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last().Err)
+		}
+		// Server spans only report errors for 5xx, 4xx are the client's fault
+		if status >= 500 {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+	}
+}
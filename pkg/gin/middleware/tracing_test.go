@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingSkipPaths(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	r := newTestRouter(Tracing(&TracingConfig{TracerProvider: tp, SkipPaths: []string{"/healthz", "/internal/*"}}))
+	r.GET("/healthz", ok)
+	r.GET("/internal/debug", ok)
+	r.GET("/orders/:id", ok)
+
+	for _, path := range []string{"/healthz", "/internal/debug", "/orders/1"} {
+		assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, path, nil)), http.StatusOK)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want only the /orders/1 span", len(spans))
+	}
+	if got, want := spans[0].Name, "GET /orders/:id"; got != want {
+		t.Fatalf("span name = %q, want %q", got, want)
+	}
+}
+
+func TestTracingContinuesTraceparent(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	r := newTestRouter(Tracing(&TracingConfig{TracerProvider: tp}))
+	r.GET("/", ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	serve(r, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := spans[0].SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace ID = %s, want the caller's", got)
+	}
+	if got := spans[0].Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Fatalf("parent span ID = %s, want the caller's", got)
+	}
+}
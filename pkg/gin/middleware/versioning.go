@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+)
+
+// Deprecation describes a deprecated API version
+type Deprecation struct {
+	Since  time.Time // sent in the Deprecation header (RFC 9745), zero uses the time Versioning is set up
+	Sunset time.Time // sent in the Sunset header (RFC 8594) when set
+	Link   string    // migration guide, sent as Link rel="deprecation"
+}
+
+// VersioningConfig configures the Versioning middleware
+type VersioningConfig struct {
+	Supported []string // e.g. "1", "2"
+	// Default applies to requests that don't ask for a version. Empty rejects them.
+	Default string
+
+	// Sources, tried in this order; disable one by leaving it empty/false
+	URLPrefix   bool   // first path segment like "/v2/orders"
+	AcceptParam string // media type parameter, e.g. "Accept: application/json; version=2"
+	Header      string // e.g. "API-Version: 2"
+
+	Deprecated map[string]Deprecation // keyed by version
+}
+
+// DefaultVersioningConfig reads the version from the URL prefix, the Accept
+// "version" parameter or the API-Version header, in that order
+func DefaultVersioningConfig() *VersioningConfig {
+	return &VersioningConfig{
+		Supported:   []string{"1"},
+		Default:     "1",
+		URLPrefix:   true,
+		AcceptParam: "version",
+		Header:      "API-Version",
+	}
+}
+
+// Versioning resolves the requested API version and stores it under
+// APIVersionKey, see GetAPIVersion and ByVersion. Versions are compared without
+// a leading "v". Unsupported versions are rejected with 400 UnsupportedAPIVersion,
+// or 406 when requested through Accept. Deprecated versions get Deprecation,
+// Sunset and Link headers.
+func Versioning(cfg *VersioningConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = DefaultVersioningConfig()
+	}
+	supported := make([]string, len(cfg.Supported))
+	for i, v := range cfg.Supported {
+		supported[i] = normalizeVersion(v)
+	}
+	now := time.Now()
+	deprecated := make(map[string]Deprecation, len(cfg.Deprecated))
+	for v, d := range cfg.Deprecated {
+		if d.Since.IsZero() {
+			d.Since = now
+		}
+		deprecated[normalizeVersion(v)] = d
+	}
+
+	return func(c *gin.Context) {
+		version, source := requestedVersion(c, cfg)
+		if version == "" {
+			if cfg.Default == "" {
+				abortWithError(c, http.StatusBadRequest, constants.UnsupportedAPIVersion, "API version is required", nil)
+				return
+			}
+			version = normalizeVersion(cfg.Default)
+		}
+
+		if !slices.Contains(supported, version) {
+			status := http.StatusBadRequest
+			if source == "accept" {
+				status = http.StatusNotAcceptable
+			}
+			abortWithError(c, status, constants.UnsupportedAPIVersion,
+				"unsupported API version "+strconv.Quote(version)+", supported: "+strings.Join(supported, ", "), nil)
+			return
+		}
+
+		if cfg.AcceptParam != "" {
+			c.Writer.Header().Add("Vary", "Accept")
+		}
+		if cfg.Header != "" {
+			c.Writer.Header().Add("Vary", cfg.Header)
+			c.Header(cfg.Header, version)
+		}
+		if d, ok := deprecated[version]; ok {
+			setDeprecationHeaders(c, d)
+		}
+
+		c.Set(APIVersionKey, version)
+		c.Next()
+	}
+}
+
+// GetAPIVersion returns the version resolved by Versioning, without a leading "v"
+func GetAPIVersion(c *gin.Context) string {
+	return c.GetString(APIVersionKey)
+}
+
+// ByVersion dispatches to the handler for the resolved version, for routes
+// shared by several versions. Versions without a handler get 400.
+func ByVersion(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	normalized := make(map[string]gin.HandlerFunc, len(handlers))
+	for v, h := range handlers {
+		normalized[normalizeVersion(v)] = h
+	}
+	return func(c *gin.Context) {
+		h, ok := normalized[GetAPIVersion(c)]
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, constants.UnsupportedAPIVersion,
+				"this endpoint is not available in API version "+strconv.Quote(GetAPIVersion(c)), nil)
+			return
+		}
+		h(c)
+	}
+}
+
+// requestedVersion returns the version the client asked for and where it came from
+func requestedVersion(c *gin.Context, cfg *VersioningConfig) (string, string) {
+	if cfg.URLPrefix {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(c.Request.URL.Path, "/"), "/")
+		if len(segment) > 1 && (segment[0] == 'v' || segment[0] == 'V') && segment[1] >= '0' && segment[1] <= '9' {
+			return normalizeVersion(segment), "url"
+		}
+	}
+	if cfg.AcceptParam != "" {
+		for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+			if err == nil && params[cfg.AcceptParam] != "" {
+				return normalizeVersion(params[cfg.AcceptParam]), "accept"
+			}
+		}
+	}
+	if cfg.Header != "" {
+		if v := c.GetHeader(cfg.Header); v != "" {
+			return normalizeVersion(v), "header"
+		}
+	}
+	return "", ""
+}
+
+func setDeprecationHeaders(c *gin.Context, d Deprecation) {
+	c.Header("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		c.Writer.Header().Add("Link", "<"+d.Link+`>; rel="deprecation"`)
+	}
+}
+
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') {
+		v = v[1:]
+	}
+	return v
+}
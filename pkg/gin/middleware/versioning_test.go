@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func versionRouter(cfg *VersioningConfig) *gin.Engine {
+	r := newTestRouter(Versioning(cfg))
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, GetAPIVersion(c))
+	}
+	r.GET("/orders", handler)
+	r.GET("/v1/orders", handler)
+	r.GET("/v2/orders", handler)
+	return r
+}
+
+func TestVersioningSources(t *testing.T) {
+	cfg := DefaultVersioningConfig()
+	cfg.Supported = []string{"v1", "2"}
+	r := versionRouter(cfg)
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		value  string
+		want   string
+	}{
+		{"default", "/orders", "", "", "1"},
+		{"url prefix", "/v2/orders", "", "", "2"},
+		{"accept param", "/orders", "Accept", "application/json; version=2", "2"},
+		{"header", "/orders", "API-Version", "v2", "2"},
+		{"url wins over header", "/v1/orders", "API-Version", "2", "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := serve(r, req)
+			assertStatus(t, w, http.StatusOK)
+			if got := w.Body.String(); got != tt.want {
+				t.Fatalf("version = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersioningRejectsUnsupported(t *testing.T) {
+	r := versionRouter(nil)
+
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, "/v2/orders", nil)), http.StatusBadRequest)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Accept", "application/json; version=2")
+	assertStatus(t, serve(r, req), http.StatusNotAcceptable)
+
+	cfg := DefaultVersioningConfig()
+	cfg.Default = ""
+	assertStatus(t, serve(versionRouter(cfg), httptest.NewRequest(http.MethodGet, "/orders", nil)), http.StatusBadRequest)
+}
+
+func TestVersioningDeprecationHeaders(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	cfg := DefaultVersioningConfig()
+	cfg.Supported = []string{"1", "2"}
+	cfg.Deprecated = map[string]Deprecation{
+		"v1": {Since: since, Sunset: sunset, Link: "https://example.com/migrate"},
+	}
+	r := versionRouter(cfg)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/v1/orders", nil))
+	if got, want := w.Header().Get("Deprecation"), "@"+strconv.FormatInt(since.Unix(), 10); got != want {
+		t.Fatalf("Deprecation = %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get("Sunset"), "Wed, 01 Jul 2026 00:00:00 GMT"; got != want {
+		t.Fatalf("Sunset = %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get("Link"), `<https://example.com/migrate>; rel="deprecation"`; got != want {
+		t.Fatalf("Link = %q, want %q", got, want)
+	}
+
+	w = serve(r, httptest.NewRequest(http.MethodGet, "/v2/orders", nil))
+	if got := w.Header().Get("Deprecation"); got != "" {
+		t.Fatalf("Deprecation on a current version = %q", got)
+	}
+}
+
+func TestVersioningDeprecationWithoutSince(t *testing.T) {
+	cfg := DefaultVersioningConfig()
+	cfg.Deprecated = map[string]Deprecation{"1": {}}
+	before := time.Now().Unix()
+	r := versionRouter(cfg)
+
+	w := serve(r, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	value, ok := strings.CutPrefix(w.Header().Get("Deprecation"), "@")
+	if !ok {
+		t.Fatalf("Deprecation = %q, want an @epoch date", w.Header().Get("Deprecation"))
+	}
+	epoch, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || epoch < before || epoch > time.Now().Unix() {
+		t.Fatalf("Deprecation = %q, want the setup time", w.Header().Get("Deprecation"))
+	}
+}
+
+func TestByVersion(t *testing.T) {
+	cfg := DefaultVersioningConfig()
+	cfg.Supported = []string{"1", "2"}
+	r := newTestRouter(Versioning(cfg))
+	r.GET("/v1/orders", ByVersion(map[string]gin.HandlerFunc{"v1": ok}))
+	r.GET("/v2/orders", ByVersion(map[string]gin.HandlerFunc{"v1": ok}))
+
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, "/v1/orders", nil)), http.StatusOK)
+	assertStatus(t, serve(r, httptest.NewRequest(http.MethodGet, "/v2/orders", nil)), http.StatusBadRequest)
+}
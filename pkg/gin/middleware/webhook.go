@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	"github.com/nhstop/go-utils/pkg/webhook"
+)
+
+// WebhookConfig configures the VerifyWebhook middleware
+type WebhookConfig struct {
+	Scheme webhook.Scheme // defaults to webhook.DefaultScheme()
+	// Secrets accepted for the signature. List the new secret next to the old
+	// one while rotating, then drop the old one.
+	Secrets  []string
+	MaxBytes int64 // largest body read for verification, defaults to 1MB
+}
+
+// VerifyWebhook rejects requests whose signature over the raw body doesn't
+// match any secret, or whose timestamp is outside the scheme's tolerance, with
+// 401 WebhookSignatureInvalid. The body is restored for the handler. Webhook
+// routes have no CSRF token, add them to CSRFConfig.ExemptPaths.
+func VerifyWebhook(cfg *WebhookConfig) gin.HandlerFunc {
+	if cfg == nil || len(cfg.Secrets) == 0 {
+		panic("middleware: WebhookConfig.Secrets is required")
+	}
+	if slices.Contains(cfg.Secrets, "") {
+		panic("middleware: WebhookConfig.Secrets must not contain empty secrets")
+	}
+	if cfg.Scheme.SignatureHeader == "" {
+		cfg.Scheme = webhook.DefaultScheme()
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 1 << 20
+	}
+
+	return func(c *gin.Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, cfg.MaxBytes+1))
+			if err != nil {
+				abortWithError(c, http.StatusBadRequest, constants.InvalidRequest, "failed to read request body", err)
+				return
+			}
+		}
+		if int64(len(body)) > cfg.MaxBytes {
+			abortWithError(c, http.StatusRequestEntityTooLarge, constants.RequestTooLarge,
+				fmt.Sprintf("request body must not be larger than %d bytes", cfg.MaxBytes), nil)
+			return
+		}
+
+		err := cfg.Scheme.Verify(c.Request.Header, body, cfg.Secrets, time.Now())
+		switch {
+		case err == nil:
+		case errors.Is(err, webhook.ErrMissingSignature):
+			abortWithError(c, http.StatusUnauthorized, constants.WebhookSignatureInvalid, cfg.Scheme.SignatureHeader+" header is required", nil)
+			return
+		case errors.Is(err, webhook.ErrInvalidTimestamp), errors.Is(err, webhook.ErrTimestampExpired):
+			abortWithError(c, http.StatusUnauthorized, constants.WebhookSignatureInvalid, "webhook timestamp is missing or too old", nil)
+			return
+		default:
+			abortWithError(c, http.StatusUnauthorized, constants.WebhookSignatureInvalid, "invalid webhook signature", nil)
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/webhook"
+)
+
+func webhookRouter(cfg *WebhookConfig) *gin.Engine {
+	r := newTestRouter()
+	r.POST("/hooks", VerifyWebhook(cfg), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return r
+}
+
+func webhookRequest(body, signature string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(body))
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+	return req
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	scheme := webhook.GitHubScheme()
+	r := webhookRouter(&WebhookConfig{Scheme: scheme, Secrets: []string{"secret"}})
+	body := `{"action":"opened"}`
+
+	w := serve(r, webhookRequest(body, scheme.Sign([]byte(body), "secret", time.Time{})))
+	assertStatus(t, w, http.StatusOK)
+	if w.Body.String() != body {
+		t.Fatalf("handler read %q, want the original body", w.Body.String())
+	}
+
+	assertStatus(t, serve(r, webhookRequest(body, "")), http.StatusUnauthorized)
+	assertStatus(t, serve(r, webhookRequest(body, scheme.Sign([]byte(body), "other", time.Time{}))), http.StatusUnauthorized)
+}
+
+func TestVerifyWebhookRejectsPrefixOnlySignature(t *testing.T) {
+	r := webhookRouter(&WebhookConfig{Scheme: webhook.GitHubScheme(), Secrets: []string{"secret"}})
+
+	assertStatus(t, serve(r, webhookRequest("", "sha256=")), http.StatusUnauthorized)
+	assertStatus(t, serve(r, webhookRequest("{}", "sha256=")), http.StatusUnauthorized)
+}
+
+func TestVerifyWebhookTimestamp(t *testing.T) {
+	scheme := webhook.DefaultScheme()
+	r := webhookRouter(&WebhookConfig{Secrets: []string{"secret"}})
+	body := []byte("{}")
+
+	req := webhookRequest(string(body), "")
+	scheme.SignRequest(req, body, "secret", time.Now())
+	assertStatus(t, serve(r, req), http.StatusOK)
+
+	req = webhookRequest(string(body), "")
+	scheme.SignRequest(req, body, "secret", time.Now().Add(-time.Hour))
+	assertStatus(t, serve(r, req), http.StatusUnauthorized)
+}
+
+func TestVerifyWebhookMaxBytes(t *testing.T) {
+	scheme := webhook.GitHubScheme()
+	r := webhookRouter(&WebhookConfig{Scheme: scheme, Secrets: []string{"secret"}, MaxBytes: 8})
+	body := strings.Repeat("x", 9)
+
+	assertStatus(t, serve(r, webhookRequest(body, scheme.Sign([]byte(body), "secret", time.Time{}))), http.StatusRequestEntityTooLarge)
+}
+
+func TestVerifyWebhookConfig(t *testing.T) {
+	assertPanics(t, "nil config", func() { VerifyWebhook(nil) })
+	assertPanics(t, "empty secret", func() { VerifyWebhook(&WebhookConfig{Secrets: []string{"secret", ""}}) })
+}
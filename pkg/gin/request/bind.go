@@ -0,0 +1,197 @@
+package request
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+)
+
+// maxMultipartMemory matches gin's default for multipart forms
+const maxMultipartMemory = 32 << 20
+
+// BindJSON decodes the JSON body into T, normalizes and validates it.
+// Errors are BadRequest-shaped CodedErrors ready for c.Error.
+//
+//	req, err := request.BindJSON[CreateUserRequest](c)
+//	if err != nil {
+//		c.Error(err)
+//		return
+//	}
+func BindJSON[T any](c *gin.Context) (T, error) {
+	var req T
+	if err := decodeJSON(c, &req); err != nil {
+		return req, apperr.BadRequest(err)
+	}
+	return req, finish(&req)
+}
+
+// BindQuery binds query parameters into T. Only fields with a `form` tag are
+// bound, not ones matching a parameter by their Go name.
+func BindQuery[T any](c *gin.Context) (T, error) {
+	var req T
+	if err := binding.MapFormWithTag(&req, queryValues(c, &req), "form"); err != nil {
+		return req, apperr.BadRequest(err)
+	}
+	return req, finish(&req)
+}
+
+// BindURI binds path parameters into T using `uri` tags
+func BindURI[T any](c *gin.Context) (T, error) {
+	var req T
+	if err := bindURI(c, &req); err != nil {
+		return req, apperr.BadRequest(err)
+	}
+	return req, finish(&req)
+}
+
+// Bind merges every request source into T before validating once, so a struct
+// can mix `json`/`form` body fields, `form` query fields, `header` fields and
+// `uri` path fields. Later sources win: query, body, headers, then path, so a
+// query parameter can't override what the body sent. Query parameters only
+// bind fields with an explicit `form` tag, and `default=` values only fill
+// fields the body left unset.
+func Bind[T any](c *gin.Context) (T, error) {
+	var req T
+	if err := bindBody(c, &req, queryValues(c, &req)); err != nil {
+		return req, apperr.BadRequest(err)
+	}
+	if err := bindHeaders(c, &req); err != nil {
+		return req, apperr.BadRequest(err)
+	}
+	if err := bindURI(c, &req); err != nil {
+		return req, apperr.BadRequest(err)
+	}
+	return req, finish(&req)
+}
+
+// finish normalizes then validates with gin's validator (`binding` tags)
+func finish(ptr any) error {
+	normalize(reflect.ValueOf(ptr))
+	if binding.Validator == nil {
+		return nil
+	}
+	if err := binding.Validator.ValidateStruct(ptr); err != nil {
+		return apperr.BadRequest(err)
+	}
+	return nil
+}
+
+func decodeJSON(c *gin.Context, ptr any) error {
+	if c.Request.Body == nil {
+		return json.NewDecoder(http.NoBody).Decode(ptr)
+	}
+	dec := json.NewDecoder(c.Request.Body)
+	if binding.EnableDecoderUseNumber {
+		dec.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(ptr)
+}
+
+// bindBody binds query, then a JSON or form body over it. Form bodies share
+// `form` tags with the query, so both are bound in one pass with body values
+// winning, and defaults don't overwrite query values the body didn't repeat.
+func bindBody(c *gin.Context, ptr any, query map[string][]string) error {
+	r := c.Request
+	if r.Body == nil || r.Body == http.NoBody || (r.ContentLength == 0 && len(r.TransferEncoding) == 0) {
+		return binding.MapFormWithTag(ptr, query, "form")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if err := binding.MapFormWithTag(ptr, query, "form"); err != nil {
+			return err
+		}
+		return decodeJSON(c, ptr)
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+		return binding.MapFormWithTag(ptr, mergeValues(query, r.PostForm), "form")
+	case mediaType == "multipart/form-data":
+		if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+			return err
+		}
+		return binding.MapFormWithTag(ptr, mergeValues(query, r.MultipartForm.Value), "form")
+	default:
+		return binding.MapFormWithTag(ptr, query, "form")
+	}
+}
+
+// mergeValues returns base with every key in override replaced
+func mergeValues(base, override map[string][]string) map[string][]string {
+	merged := make(map[string][]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// queryValues returns the query parameters named by `form` tags on ptr's type.
+// gin falls back to the Go field name for untagged fields, so passing the
+// whole query would let e.g. ?Email= set a field only meant for the body.
+func queryValues(c *gin.Context, ptr any) map[string][]string {
+	query := c.Request.URL.Query()
+	values := make(map[string][]string)
+	collectTags(reflect.TypeOf(ptr), "form", func(name string) {
+		if v, ok := query[name]; ok {
+			values[name] = v
+		}
+	})
+	return values
+}
+
+func bindURI(c *gin.Context, ptr any) error {
+	params := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = []string{p.Value}
+	}
+	return binding.MapFormWithTag(ptr, params, "uri")
+}
+
+// bindHeaders looks headers up by each `header` tag so tags don't need to be
+// in canonical form (e.g. "X-Request-ID")
+func bindHeaders(c *gin.Context, ptr any) error {
+	values := make(map[string][]string)
+	collectTags(reflect.TypeOf(ptr), "header", func(name string) {
+		if v := c.Request.Header.Values(name); len(v) > 0 {
+			values[name] = v
+		}
+	})
+	if len(values) == 0 {
+		return nil
+	}
+	return binding.MapFormWithTag(ptr, values, "header")
+}
+
+// collectTags calls fn for every tag value on t's fields, including embedded structs
+func collectTags(t reflect.Type, tag string, fn func(string)) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			collectTags(f.Type, tag, fn)
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			fn(name)
+		}
+	}
+}
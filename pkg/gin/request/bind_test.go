@@ -0,0 +1,132 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/constants"
+	apperr "github.com/nhstop/go-utils/pkg/error"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func testContext(method, target, contentType, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		c.Request.Header.Set("Content-Type", contentType)
+	}
+	return c
+}
+
+type updateUserRequest struct {
+	ID        string `uri:"id" binding:"required"`
+	Email     string `json:"email" normalize:"email" binding:"omitempty,email"`
+	Name      string `json:"name" form:"name"`
+	Page      int    `json:"page" form:"page,default=1"`
+	Sort      string `form:"sort,default=created_at"`
+	RequestID string `header:"X-Request-ID"`
+}
+
+func TestBindBodyWinsOverQuery(t *testing.T) {
+	c := testContext(http.MethodPatch, "/users/42?name=query&page=3&sort=name", "application/json", `{"name":"body"}`)
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+	c.Request.Header.Set("X-Request-ID", "req-1")
+
+	req, err := Bind[updateUserRequest](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := updateUserRequest{ID: "42", Name: "body", Page: 3, Sort: "name", RequestID: "req-1"}
+	if req != want {
+		t.Fatalf("Bind = %+v, want %+v", req, want)
+	}
+}
+
+func TestBindQueryOnlyFillsTaggedFields(t *testing.T) {
+	c := testContext(http.MethodPatch, "/users/42?Email=attacker@example.com&ID=1", "application/json", `{"email":" User@Example.com "}`)
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	req, err := Bind[updateUserRequest](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Email != "user@example.com" || req.ID != "42" {
+		t.Fatalf("Bind = %+v, untagged fields were bound from the query", req)
+	}
+
+	// ID has no form tag, so ?ID= must not satisfy its required rule
+	q, err := BindQuery[updateUserRequest](testContext(http.MethodGet, "/?Email=x&ID=1&name=a", "", ""))
+	if err == nil || q.Email != "" || q.ID != "" || q.Name != "a" {
+		t.Fatalf("BindQuery = %+v, %v, want only tagged fields bound", q, err)
+	}
+}
+
+func TestBindDefaultsDontClobberBody(t *testing.T) {
+	c := testContext(http.MethodPatch, "/users/42", "application/json", `{"page":5}`)
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	req, err := Bind[updateUserRequest](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Page != 5 || req.Sort != "created_at" {
+		t.Fatalf("Bind = %+v, want the body's page and the default sort", req)
+	}
+}
+
+func TestBindFormBodyWinsOverQuery(t *testing.T) {
+	c := testContext(http.MethodPost, "/users/42?page=3&name=query", "application/x-www-form-urlencoded", "name=body")
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	req, err := Bind[updateUserRequest](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "body" || req.Page != 3 {
+		t.Fatalf("Bind = %+v, want name from the body and page from the query", req)
+	}
+}
+
+func TestBindValidationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"invalid email", `{"email":"not-an-email"}`, http.StatusBadRequest},
+		{"malformed JSON", `{"email":`, http.StatusBadRequest},
+		{"empty body", ``, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BindJSON[updateUserRequest](testContext(http.MethodPost, "/", "application/json", tt.body))
+			var coded *apperr.CodedError
+			if !errors.As(err, &coded) {
+				t.Fatalf("error = %v, want a CodedError", err)
+			}
+			if coded.HTTPCode != tt.want || coded.Code != constants.InvalidRequest {
+				t.Fatalf("error = %d/%d, want %d/%d", coded.HTTPCode, coded.Code, tt.want, constants.InvalidRequest)
+			}
+		})
+	}
+}
+
+func TestBindURI(t *testing.T) {
+	c := testContext(http.MethodGet, "/users/42", "", "")
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+	req, err := BindURI[updateUserRequest](c)
+	if err != nil || req.ID != "42" {
+		t.Fatalf("BindURI = %+v, %v", req, err)
+	}
+
+	if _, err := BindURI[updateUserRequest](testContext(http.MethodGet, "/users", "", "")); err == nil {
+		t.Fatal("BindURI accepted a missing required path parameter")
+	}
+}
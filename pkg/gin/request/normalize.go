@@ -0,0 +1,76 @@
+package request
+
+import (
+	"reflect"
+	"strings"
+)
+
+// normalize applies `normalize` tags to string fields, recursing into nested
+// structs, pointers and slices. Supported options, comma separated:
+//
+//	trim   strings.TrimSpace
+//	lower  strings.ToLower
+//	upper  strings.ToUpper
+//	email  trim + lower
+//
+// e.g. Email string `json:"email" normalize:"email" binding:"required,email"`
+func normalize(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			normalize(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			normalize(v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			if opts := t.Field(i).Tag.Get("normalize"); opts != "" {
+				normalizeField(field, opts)
+			}
+			normalize(field)
+		}
+	}
+}
+
+func normalizeField(field reflect.Value, opts string) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(applyNormalize(field.String(), opts))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String {
+			for i := 0; i < field.Len(); i++ {
+				field.Index(i).SetString(applyNormalize(field.Index(i).String(), opts))
+			}
+		}
+	}
+}
+
+func applyNormalize(s, opts string) string {
+	for _, opt := range strings.Split(opts, ",") {
+		switch strings.TrimSpace(opt) {
+		case "trim":
+			s = strings.TrimSpace(s)
+		case "lower":
+			s = strings.ToLower(s)
+		case "upper":
+			s = strings.ToUpper(s)
+		case "email":
+			s = strings.ToLower(strings.TrimSpace(s))
+		}
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+package response
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query parameters used for pagination
+const (
+	PageParam    = "page"
+	PerPageParam = "per_page"
+	CursorParam  = "cursor"
+	LimitParam   = "limit"
+)
+
+// Pagination is metadata for a page of results, see OffsetMeta and CursorMeta
+type Pagination interface {
+	links(u *url.URL) []link
+}
+
+type link struct {
+	rel string
+	uri string
+}
+
+// formatLinks renders an RFC 8288 Link header value
+func formatLinks(links []link) string {
+	parts := make([]string, 0, len(links))
+	for _, l := range links {
+		parts = append(parts, fmt.Sprintf(`<%s>; rel="%s"`, l.uri, l.rel))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// withQuery returns u's path and query with params replaced
+func withQuery(u *url.URL, params map[string]string) string {
+	q := u.Query()
+	for k, v := range params {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+	ref := url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: q.Encode()}
+	return ref.String()
+}
+
+// -------------------------
+// Offset pagination
+// -------------------------
+
+// OffsetParams are page based pagination parameters
+type OffsetParams struct {
+	Page    int
+	PerPage int
+}
+
+// Offset is the number of rows to skip, for SQL OFFSET
+func (p OffsetParams) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// ParseOffset reads ?page=&per_page=, clamping per_page to maxPerPage and
+// page so that Offset can't overflow
+func ParseOffset(c *gin.Context, defaultPerPage, maxPerPage int) OffsetParams {
+	p := OffsetParams{
+		Page:    queryInt(c, PageParam, 1),
+		PerPage: queryInt(c, PerPageParam, defaultPerPage),
+	}
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = defaultPerPage
+	}
+	if maxPerPage > 0 && p.PerPage > maxPerPage {
+		p.PerPage = maxPerPage
+	}
+	if p.PerPage > 0 && p.Page > math.MaxInt32/p.PerPage {
+		p.Page = math.MaxInt32 / p.PerPage
+	}
+	return p
+}
+
+// OffsetMeta describes a page of offset paginated results
+type OffsetMeta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewOffsetMeta builds metadata from the request parameters and total row count
+func NewOffsetMeta(p OffsetParams, total int64) *OffsetMeta {
+	totalPages := 0
+	if p.PerPage > 0 {
+		totalPages = int((total + int64(p.PerPage) - 1) / int64(p.PerPage))
+	}
+	return &OffsetMeta{
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+func (m *OffsetMeta) links(u *url.URL) []link {
+	if m == nil {
+		return nil
+	}
+	page := func(n int) string {
+		return withQuery(u, map[string]string{
+			PageParam:    strconv.Itoa(n),
+			PerPageParam: strconv.Itoa(m.PerPage),
+		})
+	}
+
+	links := []link{{rel: "first", uri: page(1)}}
+	if m.Page > 1 {
+		links = append(links, link{rel: "prev", uri: page(min(m.Page-1, max(m.TotalPages, 1)))})
+	}
+	if m.Page < m.TotalPages {
+		links = append(links, link{rel: "next", uri: page(m.Page + 1)})
+	}
+	return append(links, link{rel: "last", uri: page(max(m.TotalPages, 1))})
+}
+
+// -------------------------
+// Cursor pagination
+// -------------------------
+
+// CursorParams are cursor based pagination parameters
+type CursorParams struct {
+	Cursor string
+	Limit  int
+}
+
+// ParseCursor reads ?cursor=&limit=, clamping limit to maxLimit
+func ParseCursor(c *gin.Context, defaultLimit, maxLimit int) CursorParams {
+	p := CursorParams{
+		Cursor: c.Query(CursorParam),
+		Limit:  queryInt(c, LimitParam, defaultLimit),
+	}
+	if p.Limit < 1 {
+		p.Limit = defaultLimit
+	}
+	if maxLimit > 0 && p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	return p
+}
+
+// CursorMeta describes a page of cursor paginated results.
+// Fetch Limit+1 rows to know whether HasMore is true.
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+func (m *CursorMeta) links(u *url.URL) []link {
+	if m == nil {
+		return nil
+	}
+	cursor := func(value string) string {
+		return withQuery(u, map[string]string{
+			CursorParam: value,
+			LimitParam:  strconv.Itoa(m.Limit),
+		})
+	}
+
+	links := []link{{rel: "first", uri: cursor("")}}
+	if m.PrevCursor != "" {
+		links = append(links, link{rel: "prev", uri: cursor(m.PrevCursor)})
+	}
+	if m.HasMore && m.NextCursor != "" {
+		links = append(links, link{rel: "next", uri: cursor(m.NextCursor)})
+	}
+	return links
+}
+
+func queryInt(c *gin.Context, key string, def int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,56 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Envelope is the success counterpart of the ErrorHandler failure shape
+type Envelope struct {
+	Success bool `json:"success"`
+	Data    any  `json:"data"`
+	Meta    any  `json:"meta,omitempty"`
+}
+
+// JSON writes data wrapped in a success envelope with the given status
+func JSON(c *gin.Context, status int, data any, meta any) {
+	c.JSON(status, Envelope{
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
+// OK responds 200 with data
+func OK(c *gin.Context, data any) {
+	JSON(c, http.StatusOK, data, nil)
+}
+
+// Created responds 201 with data, setting Location when location is not empty
+func Created(c *gin.Context, location string, data any) {
+	if location != "" {
+		c.Header("Location", location)
+	}
+	JSON(c, http.StatusCreated, data, nil)
+}
+
+// NoContent responds 204 without a body
+func NoContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
+// Paginated responds 200 with a page of data, its pagination metadata and
+// RFC 8288 Link headers for navigating between pages. A nil page responds
+// without metadata or links.
+func Paginated(c *gin.Context, data any, page Pagination) {
+	if page == nil {
+		JSON(c, http.StatusOK, data, nil)
+		return
+	}
+	if links := page.links(c.Request.URL); len(links) > 0 {
+		// Added so Link values set by middleware, e.g. deprecation, are kept
+		c.Writer.Header().Add("Link", formatLinks(links))
+	}
+	JSON(c, http.StatusOK, data, page)
+}
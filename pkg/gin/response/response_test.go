@@ -0,0 +1,72 @@
+package response
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPaginatedKeepsExistingLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/orders", func(c *gin.Context) {
+		// As set by the Versioning middleware for deprecated versions
+		c.Writer.Header().Add("Link", `<https://example.com/migrate>; rel="deprecation"`)
+		p := ParseOffset(c, 10, 100)
+		Paginated(c, []int{}, NewOffsetMeta(p, 35))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders?page=2", nil))
+
+	links := strings.Join(w.Header().Values("Link"), ", ")
+	for _, rel := range []string{`rel="deprecation"`, `rel="next"`, `rel="prev"`, `rel="last"`} {
+		if !strings.Contains(links, rel) {
+			t.Fatalf("Link %q is missing %s", links, rel)
+		}
+	}
+}
+
+func TestPaginatedWithoutMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/orders", func(c *gin.Context) {
+		Paginated(c, []int{1}, nil)
+	})
+	r.GET("/typed-nil", func(c *gin.Context) {
+		var meta *CursorMeta
+		Paginated(c, []int{1}, meta)
+	})
+
+	for _, path := range []string{"/orders", "/typed-nil"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Header().Get("Link") != "" {
+			t.Fatalf("%s: status %d, Link %q", path, w.Code, w.Header().Get("Link"))
+		}
+	}
+}
+
+func TestParseOffsetBoundsPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := map[string]OffsetParams{
+		"/?page=0&per_page=0":                    {Page: 1, PerPage: 10},
+		"/?page=3&per_page=500":                  {Page: 3, PerPage: 100},
+		"/?page=9223372036854775807&per_page=50": {Page: math.MaxInt32 / 50, PerPage: 50},
+	}
+	for target, want := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		p := ParseOffset(c, 10, 100)
+		if p != want {
+			t.Errorf("ParseOffset(%s) = %+v, want %+v", target, p, want)
+		}
+		if p.Offset() < 0 {
+			t.Errorf("ParseOffset(%s).Offset() = %d", target, p.Offset())
+		}
+	}
+}
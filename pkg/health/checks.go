@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresCheck pings the pool and reports its connection stats
+func PostgresCheck(pool *pgxpool.Pool) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		stat := pool.Stat()
+		details := map[string]any{
+			"total_conns":    stat.TotalConns(),
+			"idle_conns":     stat.IdleConns(),
+			"acquired_conns": stat.AcquiredConns(),
+			"max_conns":      stat.MaxConns(),
+			"acquire_count":  stat.AcquireCount(),
+			"empty_acquires": stat.EmptyAcquireCount(),
+		}
+		return details, pool.Ping(ctx)
+	}
+}
+
+// SQSCheck checks that queueURL is reachable and reports its approximate depth
+func SQSCheck(client *sqs.Client, queueURL string) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		out, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl: aws.String(queueURL),
+			AttributeNames: []types.QueueAttributeName{
+				types.QueueAttributeNameApproximateNumberOfMessages,
+				types.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"messages":           out.Attributes[string(types.QueueAttributeNameApproximateNumberOfMessages)],
+			"messages_in_flight": out.Attributes[string(types.QueueAttributeNameApproximateNumberOfMessagesNotVisible)],
+		}, nil
+	}
+}
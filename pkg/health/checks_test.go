@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestSQSCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AmazonSQS.GetQueueAttributes" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		json.NewEncoder(w).Encode(map[string]any{"Attributes": map[string]string{
+			"ApproximateNumberOfMessages":           "7",
+			"ApproximateNumberOfMessagesNotVisible": "2",
+		}})
+	}))
+	defer srv.Close()
+	client := sqs.New(sqs.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+
+	details, err := SQSCheck(client, srv.URL+"/000000000000/orders")(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details["messages"] != "7" || details["messages_in_flight"] != "2" {
+		t.Fatalf("details = %v", details)
+	}
+}
+
+func TestSQSCheckFailsWhenUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	client := sqs.New(sqs.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(srv.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+
+	if _, err := SQSCheck(client, srv.URL+"/000000000000/orders")(context.Background()); err == nil {
+		t.Fatal("SQSCheck passed against a closed server")
+	}
+}
+
+func TestPostgresCheck(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	details, err := PostgresCheck(pool)(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := details["max_conns"]; !ok {
+		t.Fatalf("details = %v, want pool stats", details)
+	}
+}
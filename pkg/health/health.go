@@ -0,0 +1,248 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/logger"
+)
+
+// Statuses reported for checks and overall health
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout bounds checks registered without their own timeout
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc checks one dependency. Details are included in the report, e.g. pool stats.
+type CheckFunc func(ctx context.Context) (details map[string]any, err error)
+
+// Check is a named CheckFunc with its own timeout
+type Check struct {
+	Name    string
+	Func    CheckFunc
+	Timeout time.Duration // defaults to DefaultTimeout
+}
+
+// CheckResult is the outcome of one check. Error is a generic message, since
+// the reports are usually unauthenticated; the underlying error is logged.
+type CheckResult struct {
+	Status    string         `json:"status"`
+	LatencyMs float64        `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Report is the JSON body returned by the health handlers
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+var (
+	errFailed       = errors.New("check failed")
+	errTimeout      = errors.New("check timed out")
+	errCanceled     = errors.New("check canceled")
+	errShuttingDown = errors.New("shutting down")
+)
+
+// Health runs liveness and readiness checks.
+// Liveness answers "should this process be restarted?" and should only check
+// the process itself. Readiness answers "should this instance get traffic?"
+// and checks dependencies such as Postgres and SQS.
+type Health struct {
+	mu        sync.RWMutex
+	liveness  []Check
+	readiness []Check
+	ready     atomic.Bool
+}
+
+// New creates a Health that reports ready until SetReady(false)
+func New() *Health {
+	h := &Health{}
+	h.ready.Store(true)
+	return h
+}
+
+// AddLivenessCheck registers a check reported by the liveness endpoint.
+// It panics when another liveness check has the same name.
+func (h *Health) AddLivenessCheck(check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = addCheck(h.liveness, check)
+}
+
+// AddReadinessCheck registers a check reported by the readiness endpoint.
+// It panics when another readiness check has the same name.
+func (h *Health) AddReadinessCheck(check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = addCheck(h.readiness, check)
+}
+
+// addCheck appends check, rejecting names already in checks since results
+// are reported by name and one would hide the other
+func addCheck(checks []Check, check Check) []Check {
+	if check.Name == "" || check.Func == nil {
+		panic("health: Check.Name and Check.Func are required")
+	}
+	for _, c := range checks {
+		if c.Name == check.Name {
+			panic(fmt.Sprintf("health: duplicate check %q", check.Name))
+		}
+	}
+	return append(checks, check)
+}
+
+// SetReady flips readiness, e.g. to false when shutdown starts so load
+// balancers stop routing new requests before the server drains
+func (h *Health) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// Liveness runs the liveness checks
+func (h *Health) Liveness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := append([]Check(nil), h.liveness...)
+	h.mu.RUnlock()
+	return runChecks(ctx, checks)
+}
+
+// Readiness runs the readiness checks, failing immediately after SetReady(false)
+func (h *Health) Readiness(ctx context.Context) Report {
+	if !h.ready.Load() {
+		return Report{
+			Status: StatusFail,
+			Checks: map[string]CheckResult{
+				"ready": {Status: StatusFail, Error: errShuttingDown.Error()},
+			},
+		}
+	}
+
+	h.mu.RLock()
+	checks := append([]Check(nil), h.readiness...)
+	h.mu.RUnlock()
+	return runChecks(ctx, checks)
+}
+
+// LivenessHandler serves the liveness report, 503 when any check fails
+func (h *Health) LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeReport(c, h.Liveness(c.Request.Context()))
+	}
+}
+
+// ReadinessHandler serves the readiness report, 503 when any check fails
+func (h *Health) ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeReport(c, h.Readiness(c.Request.Context()))
+	}
+}
+
+// Register adds GET /livez, /readyz and /healthz (an alias of /readyz)
+func (h *Health) Register(r gin.IRoutes) {
+	r.GET("/livez", h.LivenessHandler())
+	r.GET("/readyz", h.ReadinessHandler())
+	r.GET("/healthz", h.ReadinessHandler())
+}
+
+func writeReport(c *gin.Context, report Report) {
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
+
+// runChecks runs checks concurrently, each bounded by its own timeout
+func runChecks(ctx context.Context, checks []Check) Report {
+	report := Report{Status: StatusOK}
+	if len(checks) == 0 {
+		return report
+	}
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report.Checks = make(map[string]CheckResult, len(checks))
+	for i, check := range checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]any
+		err     error
+	}
+	// Buffered so a check that ignores ctx doesn't leak a blocked goroutine
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := check.Func(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   out.details,
+	}
+	if out.err != nil {
+		result.Status = StatusFail
+		result.Error = publicError(ctx).Error()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			logger.Error("health check %s failed: %v", check.Name, out.err)
+		}
+	}
+	return result
+}
+
+// publicError describes a failure without the check's error, which may
+// contain DSNs or hostnames. A canceled ctx means the caller went away,
+// a deadline that the check or its caller ran out of time.
+func publicError(ctx context.Context) error {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return errCanceled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return errTimeout
+	default:
+		return errFailed
+	}
+}
@@ -0,0 +1,29 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record is a stored idempotency key and, once completed, the response to replay
+type Record struct {
+	Key         string
+	Fingerprint string // hash of the request the key was first used with
+	Completed   bool   // false while the first request is still in flight
+	Status      int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Store persists idempotency records
+type Store interface {
+	// Begin reserves key for lockTimeout. It returns nil when the key was
+	// reserved by this call, or the existing unexpired record otherwise.
+	Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*Record, error)
+	// Complete stores the response for key and keeps it for ttl
+	Complete(ctx context.Context, key string, status int, header http.Header, body []byte, ttl time.Duration) error
+	// Release drops an in-flight reservation so the request can be retried
+	Release(ctx context.Context, key string) error
+}
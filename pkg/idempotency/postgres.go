@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps idempotency records in a Postgres table
+type PostgresStore struct {
+	pool  *pgxpool.Pool
+	table string
+}
+
+// NewPostgresStore creates a PostgresStore using table (defaults to "idempotency_keys")
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	if table == "" {
+		table = "idempotency_keys"
+	}
+	return &PostgresStore{
+		pool:  pool,
+		table: pgx.Identifier{table}.Sanitize(),
+	}
+}
+
+// CreateTable creates the records table if it does not exist
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key          TEXT PRIMARY KEY,
+			fingerprint  TEXT NOT NULL,
+			completed    BOOLEAN NOT NULL DEFAULT false,
+			status       INTEGER NOT NULL DEFAULT 0,
+			headers      JSONB,
+			body         BYTEA,
+			created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+			expires_at   TIMESTAMPTZ NOT NULL
+		)`, s.table))
+	return err
+}
+
+// maxBeginAttempts bounds how often Begin retries when the existing row is
+// deleted between the INSERT and the SELECT
+const maxBeginAttempts = 3
+
+// ErrBeginContention is returned when Begin keeps losing races with deletes
+var ErrBeginContention = errors.New("idempotency: key kept changing while being reserved")
+
+// Begin reserves key, taking over rows whose reservation or retention has expired
+func (s *PostgresStore) Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*Record, error) {
+	for range maxBeginAttempts {
+		rec, err := s.begin(ctx, key, fingerprint, lockTimeout)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return rec, err
+		}
+	}
+	return nil, ErrBeginContention
+}
+
+// begin makes one attempt at reserving key. It returns pgx.ErrNoRows when the
+// existing row disappeared before it could be read.
+func (s *PostgresStore) begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*Record, error) {
+	var reserved bool
+	err := s.pool.QueryRow(ctx, fmt.Sprintf(`
+		INSERT INTO %[1]s (key, fingerprint, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			completed   = false,
+			status      = 0,
+			headers     = NULL,
+			body        = NULL,
+			created_at  = now(),
+			expires_at  = EXCLUDED.expires_at
+		WHERE %[1]s.expires_at < now()
+		RETURNING true`, s.table),
+		key, fingerprint, lockTimeout.Seconds()).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// The key exists and has not expired
+	rec := &Record{Key: key}
+	var headers []byte
+	err = s.pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT fingerprint, completed, status, headers, body, expires_at
+		FROM %s WHERE key = $1`, s.table), key).
+		Scan(&rec.Fingerprint, &rec.Completed, &rec.Status, &headers, &rec.Body, &rec.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &rec.Header); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// Complete stores the response and extends the key's lifetime to ttl
+func (s *PostgresStore) Complete(ctx context.Context, key string, status int, header http.Header, body []byte, ttl time.Duration) error {
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.Exec(ctx, fmt.Sprintf(`
+		UPDATE %s SET completed = true, status = $2, headers = $3, body = $4,
+			expires_at = now() + make_interval(secs => $5)
+		WHERE key = $1`, s.table),
+		key, status, headers, body, ttl.Seconds())
+	return err
+}
+
+// Release deletes an in-flight reservation
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE key = $1 AND NOT completed`, s.table), key)
+	return err
+}
+
+// DeleteExpired removes expired records
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expires_at < now()`, s.table))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
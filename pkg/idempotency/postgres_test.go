@@ -0,0 +1,93 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testStore connects to TEST_DATABASE_URL, skipping the test when it is unset
+func testStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	table := "idempotency_keys_test"
+	store := NewPostgresStore(pool, table)
+	if _, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+store.table); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+store.table) })
+	return store
+}
+
+func TestPostgresStoreLifecycle(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	rec, err := store.Begin(ctx, "k1", "fp", time.Minute)
+	if err != nil || rec != nil {
+		t.Fatalf("first Begin = %v, %v, want a reservation", rec, err)
+	}
+	rec, err = store.Begin(ctx, "k1", "fp", time.Minute)
+	if err != nil || rec == nil || rec.Completed {
+		t.Fatalf("Begin while in flight = %+v, %v, want the pending record", rec, err)
+	}
+
+	header := http.Header{"Location": {"/orders/1"}}
+	if err := store.Complete(ctx, "k1", http.StatusCreated, header, []byte("created"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	rec, err = store.Begin(ctx, "k1", "fp", time.Minute)
+	if err != nil || rec == nil || !rec.Completed || rec.Status != http.StatusCreated ||
+		string(rec.Body) != "created" || rec.Header.Get("Location") != "/orders/1" {
+		t.Fatalf("Begin after Complete = %+v, %v, want the stored response", rec, err)
+	}
+
+	// Completed records are kept, in-flight ones are released
+	if err := store.Release(ctx, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ := store.Begin(ctx, "k1", "fp", time.Minute); rec == nil {
+		t.Fatal("Release dropped a completed record")
+	}
+	store.Begin(ctx, "k2", "fp", time.Minute)
+	if err := store.Release(ctx, "k2"); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ := store.Begin(ctx, "k2", "fp", time.Minute); rec != nil {
+		t.Fatal("Release kept an in-flight reservation")
+	}
+}
+
+func TestPostgresStoreTakesOverExpiredKeys(t *testing.T) {
+	store := testStore(t)
+	ctx := context.Background()
+
+	if _, err := store.Begin(ctx, "k1", "old", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	rec, err := store.Begin(ctx, "k1", "new", time.Minute)
+	if err != nil || rec != nil {
+		t.Fatalf("Begin on an expired key = %+v, %v, want a new reservation", rec, err)
+	}
+	if n, err := store.DeleteExpired(ctx); err != nil || n != 0 {
+		t.Fatalf("DeleteExpired = %d, %v, want nothing expired", n, err)
+	}
+}
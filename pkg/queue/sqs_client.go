@@ -7,7 +7,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/nhstop/go-utils/pkg/logger"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type MessageEnvelope struct {
@@ -28,15 +31,40 @@ func NewSQSClient(ctx context.Context, region string) *sqs.Client {
 	return client
 }
 
+// SendMessage sends messageBody, propagating the trace context of ctx in message attributes
 func SendMessage(ctx context.Context, client *sqs.Client, queueURL, messageBody string) {
-	_, err := client.SendMessage(ctx, &sqs.SendMessageInput{
-		QueueUrl:    aws.String(queueURL),
-		MessageBody: aws.String(messageBody),
-	})
-	if err != nil {
+	if err := Send(ctx, client, queueURL, messageBody, nil); err != nil {
 		logger.Error("failed to send message: %v", err)
 		return
 	}
 
 	logger.Info("✅ Message sent successfully")
 }
+
+// SendConfig holds options for sending messages
+type SendConfig struct {
+	TracerProvider trace.TracerProvider // defaults to the global provider
+}
+
+// Send is SendMessage for callers that need to know whether the message was
+// queued, cfg may be nil
+func Send(ctx context.Context, client *sqs.Client, queueURL, messageBody string, cfg *SendConfig) error {
+	if cfg == nil {
+		cfg = &SendConfig{}
+	}
+	attributes := make(map[string]types.MessageAttributeValue)
+	ctx, span := startSendSpan(ctx, cfg.TracerProvider, queueURL, attributes)
+	defer span.End()
+
+	_, err := client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		MessageBody:       aws.String(messageBody),
+		MessageAttributes: attributes,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ReceiveConfig holds configurable options for receiving messages
@@ -16,7 +18,12 @@ type ReceiveConfig struct {
 	MaxNumberOfMessages int32
 	WaitTimeSeconds     int32
 	VisibilityTimeout   int32
-	PollInterval        time.Duration // interval to wait on errors
+	PollInterval        time.Duration        // interval to wait on errors
+	TracerProvider      trace.TracerProvider // defaults to the global provider
+	// DrainTimeout is how long in-flight handlers may keep running once ctx is
+	// cancelled before their context is cancelled too. Keep it below the
+	// server's ShutdownTimeout. Defaults to 20s.
+	DrainTimeout time.Duration
 }
 
 // DefaultReceiveConfig provides sensible defaults
@@ -26,28 +33,53 @@ func DefaultReceiveConfig() *ReceiveConfig {
 		WaitTimeSeconds:     10,
 		VisibilityTimeout:   30,
 		PollInterval:        5 * time.Second,
+		DrainTimeout:        20 * time.Second,
 	}
 }
 
 // MessageHandler is a function type for processing a single message
 type MessageHandler func(ctx context.Context, msg types.Message) error
 
-// ReceiveMessages continuously polls SQS and calls handler for each message
+// ReceiveMessages continuously polls SQS and calls handler for each message.
+// It returns once ctx is cancelled and the messages already received have been
+// handled; handlers get a context that outlives ctx by DrainTimeout so they can finish.
 func ReceiveMessages(ctx context.Context, client *sqs.Client, queueURL string, cfg *ReceiveConfig, handler MessageHandler) {
 	if cfg == nil {
 		cfg = DefaultReceiveConfig()
 	}
+	drainTimeout := cfg.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = 20 * time.Second
+	}
+
+	// In-flight messages are finished and deleted after shutdown starts, until
+	// the drain timeout runs out
+	handlerCtx, cancelHandlers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelHandlers()
+	stopDrain := context.AfterFunc(ctx, func() {
+		time.AfterFunc(drainTimeout, cancelHandlers)
+	})
+	defer stopDrain()
 
-	for {
+	for ctx.Err() == nil {
 		output, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
 			QueueUrl:            aws.String(queueURL),
 			MaxNumberOfMessages: cfg.MaxNumberOfMessages,
 			WaitTimeSeconds:     cfg.WaitTimeSeconds,
 			VisibilityTimeout:   cfg.VisibilityTimeout,
+			// Trace context travels in message attributes
+			MessageAttributeNames: []string{"All"},
 		})
 		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 			fmt.Println("❌ Error receiving messages:", err)
-			time.Sleep(cfg.PollInterval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(cfg.PollInterval):
+			}
 			continue
 		}
 
@@ -61,11 +93,16 @@ func ReceiveMessages(ctx context.Context, client *sqs.Client, queueURL string, c
 			wg.Add(1)
 			go func(m types.Message) {
 				defer wg.Done()
-				if err := handler(ctx, m); err != nil {
+				msgCtx, span := startProcessSpan(handlerCtx, cfg.TracerProvider, queueURL, m)
+				defer span.End()
+
+				if err := handler(msgCtx, m); err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
 					fmt.Println("❌ Error processing message:", err)
 				} else {
 					// Delete message after successful processing
-					_, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+					_, err := client.DeleteMessage(handlerCtx, &sqs.DeleteMessageInput{
 						QueueUrl:      aws.String(queueURL),
 						ReceiptHandle: m.ReceiptHandle,
 					})
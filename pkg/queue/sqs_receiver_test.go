@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+func TestReceiveMessagesCancelsHandlersAfterDrainTimeout(t *testing.T) {
+	fake := &fakeSQS{messages: []types.Message{{MessageId: aws.String("m1"), ReceiptHandle: aws.String("r1"), Body: aws.String("hi")}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	client := sqs.New(sqs.Options{Region: "us-east-1", BaseEndpoint: aws.String(srv.URL), Credentials: aws.AnonymousCredentials{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handlerDone := make(chan time.Time, 1)
+	start := time.Now()
+	go ReceiveMessages(ctx, client, srv.URL+"/000000000000/jobs",
+		&ReceiveConfig{MaxNumberOfMessages: 1, PollInterval: 10 * time.Millisecond, DrainTimeout: 100 * time.Millisecond},
+		func(hctx context.Context, msg types.Message) error {
+			cancel() // shutdown starts while the message is in flight
+			time.Sleep(50 * time.Millisecond)
+			if hctx.Err() != nil {
+				t.Error("handler context cancelled before the drain timeout")
+			}
+			<-hctx.Done()
+			handlerDone <- time.Now()
+			return hctx.Err()
+		})
+
+	select {
+	case at := <-handlerDone:
+		if at.Sub(start) < 100*time.Millisecond {
+			t.Fatalf("handler cancelled after %v, before the drain timeout", at.Sub(start))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler context was never cancelled")
+	}
+}
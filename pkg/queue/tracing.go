@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nhstop/go-utils/pkg/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// messageAttributeCarrier adapts SQS message attributes to propagation.TextMapCarrier
+type messageAttributeCarrier map[string]types.MessageAttributeValue
+
+func (m messageAttributeCarrier) Get(key string) string {
+	if v, ok := m[key]; ok && v.StringValue != nil {
+		return *v.StringValue
+	}
+	return ""
+}
+
+func (m messageAttributeCarrier) Set(key, value string) {
+	m[key] = types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
+}
+
+func (m messageAttributeCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// queueName returns the last path segment of a queue URL
+func queueName(queueURL string) string {
+	return queueURL[strings.LastIndex(queueURL, "/")+1:]
+}
+
+// startSendSpan starts a producer span and injects its context into attributes
+func startSendSpan(ctx context.Context, provider trace.TracerProvider, queueURL string, attributes map[string]types.MessageAttributeValue) (context.Context, trace.Span) {
+	name := queueName(queueURL)
+	ctx, span := tracing.Tracer(provider).Start(ctx, "send "+name, trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemAWSSqs,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(name),
+		),
+	)
+	tracing.Propagator().Inject(ctx, messageAttributeCarrier(attributes))
+	return ctx, span
+}
+
+// startProcessSpan continues the trace carried in a message's attributes and
+// links to the producer span, as the messaging conventions recommend
+func startProcessSpan(ctx context.Context, provider trace.TracerProvider, queueURL string, msg types.Message) (context.Context, trace.Span) {
+	name := queueName(queueURL)
+	ctx = tracing.Propagator().Extract(ctx, messageAttributeCarrier(msg.MessageAttributes))
+	return tracing.Tracer(provider).Start(ctx, "process "+name, trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(
+			semconv.MessagingSystemAWSSqs,
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingDestinationName(name),
+			semconv.MessagingMessageID(aws.ToString(msg.MessageId)),
+		),
+	)
+}
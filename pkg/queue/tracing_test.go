@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeSQS is an in-memory queue speaking the SQS JSON protocol
+type fakeSQS struct {
+	mu       sync.Mutex
+	messages []types.Message
+}
+
+func (f *fakeSQS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var resp any = struct{}{}
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSQS.SendMessage":
+		var in struct {
+			MessageBody       string
+			MessageAttributes map[string]types.MessageAttributeValue
+		}
+		json.NewDecoder(r.Body).Decode(&in)
+		f.messages = append(f.messages, types.Message{
+			MessageId:         aws.String("m1"),
+			ReceiptHandle:     aws.String("r1"),
+			Body:              aws.String(in.MessageBody),
+			MessageAttributes: in.MessageAttributes,
+		})
+		resp = map[string]string{"MessageId": "m1"}
+	case "AmazonSQS.ReceiveMessage":
+		resp = map[string][]types.Message{"Messages": f.messages}
+		f.messages = nil
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestTracePropagatesThroughMessageAttributes(t *testing.T) {
+	srv := httptest.NewServer(&fakeSQS{})
+	defer srv.Close()
+	client := sqs.New(sqs.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	queueURL := srv.URL + "/000000000000/orders"
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if err := Send(ctx, client, queueURL, "hello", &SendConfig{TracerProvider: tp}); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	recvCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var handled trace.SpanContext
+	ReceiveMessages(recvCtx, client, queueURL, &ReceiveConfig{MaxNumberOfMessages: 1, PollInterval: 10 * time.Millisecond, TracerProvider: tp},
+		func(ctx context.Context, msg types.Message) error {
+			handled = trace.SpanContextFromContext(ctx)
+			cancel()
+			return nil
+		})
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+	send, ok := spans["send orders"]
+	if !ok {
+		t.Fatalf("no producer span in %v", exporter.GetSpans())
+	}
+	process, ok := spans["process orders"]
+	if !ok {
+		t.Fatalf("no consumer span in %v", exporter.GetSpans())
+	}
+
+	if send.SpanKind != trace.SpanKindProducer || process.SpanKind != trace.SpanKindConsumer {
+		t.Fatalf("span kinds = %v, %v", send.SpanKind, process.SpanKind)
+	}
+	if send.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("producer span is not a child of the caller's span")
+	}
+	if process.SpanContext.TraceID() != send.SpanContext.TraceID() || process.Parent.SpanID() != send.SpanContext.SpanID() {
+		t.Fatal("consumer span does not continue the producer's trace")
+	}
+	if len(process.Links) != 1 || process.Links[0].SpanContext.SpanID() != send.SpanContext.SpanID() {
+		t.Fatalf("consumer span links = %v, want the producer span", process.Links)
+	}
+	if handled.SpanID() != process.SpanContext.SpanID() {
+		t.Fatal("handler context does not carry the consumer span")
+	}
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	state     state
+	expiresAt time.Time
+}
+
+// MemoryStore keeps limiter state in process memory.
+// State is not shared between instances, use PostgresStore behind a load balancer.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryStore creates a MemoryStore that evicts idle keys every cleanupInterval
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+
+	s := &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		stop:    make(chan struct{}),
+	}
+	go s.cleanup(cleanupInterval)
+	return s
+}
+
+// Take applies limit to key
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	limit, err := limit.validate()
+	if err != nil {
+		return Result{}, err
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	var res Result
+	entry.state, res = take(entry.state, limit, now)
+	entry.expiresAt = now.Add(limit.idleTTL())
+	return res, nil
+}
+
+// Close stops the background cleanup goroutine
+func (s *MemoryStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *MemoryStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, entry := range s.entries {
+				if now.After(entry.expiresAt) {
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
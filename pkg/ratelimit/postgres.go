@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps limiter state in a Postgres table so limits are shared
+// between every instance using the same database.
+type PostgresStore struct {
+	pool  *pgxpool.Pool
+	table string
+}
+
+// NewPostgresStore creates a PostgresStore using table (defaults to "rate_limits")
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	if table == "" {
+		table = "rate_limits"
+	}
+	return &PostgresStore{
+		pool:  pool,
+		table: pgx.Identifier{table}.Sanitize(),
+	}
+}
+
+// CreateTable creates the state table if it does not exist
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key          TEXT PRIMARY KEY,
+			tokens       DOUBLE PRECISION NOT NULL DEFAULT 0,
+			updated_at   TIMESTAMPTZ,
+			window_start TIMESTAMPTZ,
+			prev_count   BIGINT NOT NULL DEFAULT 0,
+			curr_count   BIGINT NOT NULL DEFAULT 0,
+			expires_at   TIMESTAMPTZ NOT NULL
+		)`, s.table))
+	return err
+}
+
+// Take applies limit to key inside a transaction holding the key's row lock.
+// The database clock is used so instances with skewed clocks agree.
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	limit, err := limit.validate()
+	if err != nil {
+		return Result{}, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(
+		`INSERT INTO %s (key, expires_at) VALUES ($1, now()) ON CONFLICT (key) DO NOTHING`, s.table), key)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var (
+		st                     state
+		updatedAt, windowStart *time.Time
+		expiresAt, now         time.Time
+	)
+	err = tx.QueryRow(ctx, fmt.Sprintf(
+		`SELECT tokens, updated_at, window_start, prev_count, curr_count, expires_at, now()
+		 FROM %s WHERE key = $1 FOR UPDATE`, s.table), key).
+		Scan(&st.Tokens, &updatedAt, &windowStart, &st.Prev, &st.Curr, &expiresAt, &now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Expired rows behave like fresh keys
+	if now.Before(expiresAt) {
+		if updatedAt != nil {
+			st.UpdatedAt = *updatedAt
+		}
+		if windowStart != nil {
+			st.WindowStart = *windowStart
+		}
+	} else {
+		st = state{}
+	}
+
+	st, res := take(st, limit, now)
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(
+		`UPDATE %s SET tokens = $2, updated_at = $3, window_start = $4,
+		 prev_count = $5, curr_count = $6, expires_at = $7 WHERE key = $1`, s.table),
+		key, st.Tokens, nullTime(st.UpdatedAt), nullTime(st.WindowStart), st.Prev, st.Curr, now.Add(limit.idleTTL()))
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// DeleteExpired removes rows for keys that have been idle long enough to be reset
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expires_at < now()`, s.table))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
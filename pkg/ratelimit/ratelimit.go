@@ -0,0 +1,165 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Algorithm selects how requests are counted against a Limit
+type Algorithm int
+
+const (
+	// TokenBucket refills Requests tokens every Period and allows bursts up to Burst
+	TokenBucket Algorithm = iota
+	// SlidingWindow weights the previous fixed window to smooth out window edges
+	SlidingWindow
+)
+
+// Limit describes how many requests a key may make
+type Limit struct {
+	Algorithm Algorithm
+	Requests  int           // requests allowed per Period
+	Period    time.Duration // e.g. time.Minute
+	Burst     int           // token bucket capacity, defaults to Requests
+}
+
+// PerMinute returns a token bucket limit of n requests per minute
+func PerMinute(n int) Limit {
+	return Limit{Algorithm: TokenBucket, Requests: n, Period: time.Minute}
+}
+
+// Result is the outcome of taking one request from a key's limit
+type Result struct {
+	Allowed    bool
+	Limit      int           // maximum requests in the current policy
+	Remaining  int           // requests left before being limited
+	ResetAfter time.Duration // time until the limit is fully replenished
+	RetryAfter time.Duration // time until the next request is allowed, 0 when allowed
+}
+
+// Store persists limiter state and applies a Limit atomically per key
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+var ErrInvalidLimit = errors.New("ratelimit: requests and period must be positive")
+
+// state is the per-key bookkeeping shared by every Store implementation
+type state struct {
+	Tokens      float64   // token bucket: tokens currently available
+	UpdatedAt   time.Time // token bucket: last refill time
+	WindowStart time.Time // sliding window: start of the current window
+	Prev        int64     // sliding window: requests in the previous window
+	Curr        int64     // sliding window: requests in the current window
+}
+
+func (l Limit) validate() (Limit, error) {
+	if l.Requests <= 0 || l.Period <= 0 {
+		return l, ErrInvalidLimit
+	}
+	if l.Burst <= 0 {
+		l.Burst = l.Requests
+	}
+	return l, nil
+}
+
+// idleTTL is how long a key must stay idle before its state equals a fresh key
+func (l Limit) idleTTL() time.Duration {
+	ttl := 2 * l.Period
+	if refill := time.Duration(float64(l.Period) * float64(l.Burst) / float64(l.Requests)); refill > ttl {
+		ttl = refill
+	}
+	return ttl
+}
+
+// take applies limit to st at time now and returns the updated state
+func take(st state, limit Limit, now time.Time) (state, Result) {
+	if limit.Algorithm == SlidingWindow {
+		return takeSlidingWindow(st, limit, now)
+	}
+	return takeTokenBucket(st, limit, now)
+}
+
+func takeTokenBucket(st state, limit Limit, now time.Time) (state, Result) {
+	capacity := float64(limit.Burst)
+	rate := float64(limit.Requests) / limit.Period.Seconds() // tokens per second
+
+	if st.UpdatedAt.IsZero() {
+		st.Tokens = capacity
+	} else if elapsed := now.Sub(st.UpdatedAt).Seconds(); elapsed > 0 {
+		st.Tokens = math.Min(capacity, st.Tokens+elapsed*rate)
+	}
+	st.UpdatedAt = now
+
+	res := Result{Limit: limit.Burst}
+	if st.Tokens >= 1 {
+		st.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - st.Tokens) / rate)
+	}
+
+	res.Remaining = int(math.Floor(st.Tokens))
+	res.ResetAfter = seconds((capacity - st.Tokens) / rate)
+	return st, res
+}
+
+func takeSlidingWindow(st state, limit Limit, now time.Time) (state, Result) {
+	start := now.Truncate(limit.Period)
+	if !st.WindowStart.Equal(start) {
+		if start.Sub(st.WindowStart) == limit.Period {
+			st.Prev = st.Curr
+		} else {
+			st.Prev = 0
+		}
+		st.Curr = 0
+		st.WindowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - elapsed.Seconds()/limit.Period.Seconds()
+	estimate := float64(st.Prev)*weight + float64(st.Curr)
+	max := float64(limit.Requests)
+
+	res := Result{Limit: limit.Requests}
+	if estimate+1 <= max {
+		st.Curr++
+		estimate++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = slidingRetryAfter(st, limit, elapsed)
+	}
+
+	res.Remaining = int(math.Max(0, math.Floor(max-estimate)))
+	res.ResetAfter = limit.Period - elapsed
+	if st.Curr > 0 {
+		// Current hits only stop counting once the next window has fully passed
+		res.ResetAfter += limit.Period
+	}
+	return st, res
+}
+
+// slidingRetryAfter computes when the weighted estimate drops enough to admit one request
+func slidingRetryAfter(st state, limit Limit, elapsed time.Duration) time.Duration {
+	allowed := float64(limit.Requests - 1)
+	period := limit.Period.Seconds()
+
+	if float64(st.Curr) <= allowed && st.Prev > 0 {
+		// Wait for the previous window's weight to decay within this window
+		t := period * (1 - (allowed-float64(st.Curr))/float64(st.Prev))
+		return seconds(t - elapsed.Seconds())
+	}
+
+	// Wait for the next window, where current hits become the decaying previous window
+	t := period * (1 - allowed/float64(st.Curr))
+	return limit.Period - elapsed + seconds(t)
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
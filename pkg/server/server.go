@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nhstop/go-utils/pkg/database"
+	"github.com/nhstop/go-utils/pkg/health"
+	"github.com/nhstop/go-utils/pkg/logger"
+	"github.com/nhstop/go-utils/pkg/queue"
+)
+
+// Config configures an App
+type Config struct {
+	Addr string // defaults to ":8080"
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ShutdownTimeout bounds draining HTTP requests and waiting for workers,
+	// which happen in parallel
+	ShutdownTimeout time.Duration
+	// ReadinessDelay is how long to keep serving after readiness starts failing,
+	// giving load balancers time to stop sending new requests
+	ReadinessDelay time.Duration
+
+	Health *health.Health // readiness is flipped to failing on shutdown
+	Pool   *pgxpool.Pool  // closed last, after HTTP and workers have drained
+}
+
+// DefaultConfig listens on :8080 and allows 30 seconds to drain
+func DefaultConfig() *Config {
+	return &Config{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+	}
+}
+
+// Worker is a background task that runs until ctx is cancelled.
+// Returning a non-nil error before then shuts the App down.
+type Worker func(ctx context.Context) error
+
+type namedWorker struct {
+	name string
+	run  Worker
+}
+
+// App runs a gin engine and background workers and shuts them down in order
+type App struct {
+	cfg     *Config
+	server  *http.Server
+	workers []namedWorker
+}
+
+// New creates an App serving engine
+func New(engine *gin.Engine, cfg *Config) *App {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = ":8080"
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+
+	return &App{
+		cfg: cfg,
+		server: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           engine,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+	}
+}
+
+// AddWorker registers a background worker started by Run
+func (a *App) AddWorker(name string, w Worker) {
+	a.workers = append(a.workers, namedWorker{name: name, run: w})
+}
+
+// QueueWorker runs queue.ReceiveMessages as a Worker
+func QueueWorker(client *sqs.Client, queueURL string, cfg *queue.ReceiveConfig, handler queue.MessageHandler) Worker {
+	return func(ctx context.Context) error {
+		queue.ReceiveMessages(ctx, client, queueURL, cfg, handler)
+		return nil
+	}
+}
+
+// Run serves until SIGINT or SIGTERM, then shuts down.
+// A second signal during shutdown exits immediately.
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return a.RunContext(ctx)
+}
+
+// RunContext serves until ctx is cancelled, the server fails or a worker
+// returns an error, then shuts down:
+//  1. workers are cancelled so they stop taking new work
+//  2. readiness starts failing and ReadinessDelay passes
+//  3. the HTTP server stops accepting connections and drains in-flight
+//     requests while the workers finish, both within ShutdownTimeout
+//  4. the database pool is closed, unless step 3 timed out
+//
+// The returned error is the one that triggered the shutdown joined with any
+// shutdown failures.
+func (a *App) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// ---------------- Start ----------------
+	go func() {
+		logger.Info("🚀 HTTP server listening on %s", a.cfg.Addr)
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cancel(err)
+		}
+	}()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var wg sync.WaitGroup
+	for _, w := range a.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Info("▶️ Worker %s started", w.name)
+			if err := w.run(workerCtx); err != nil && workerCtx.Err() == nil {
+				logger.Error("worker %s failed: %v", w.name, err)
+				cancel(err)
+				return
+			}
+			logger.Info("⏹️ Worker %s stopped", w.name)
+		}()
+	}
+
+	<-ctx.Done()
+	runErr := context.Cause(ctx)
+	if errors.Is(runErr, context.Canceled) {
+		// Cancelled by a signal or the caller, a normal shutdown
+		runErr = nil
+	}
+
+	// ---------------- Shutdown ----------------
+	logger.Info("🛑 Shutting down...")
+	// Workers don't receive traffic from the load balancer, stop them pulling
+	// new messages now rather than after the HTTP drain
+	stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	if a.cfg.Health != nil {
+		a.cfg.Health.SetReady(false)
+		if a.cfg.ReadinessDelay > 0 {
+			time.Sleep(a.cfg.ReadinessDelay)
+		}
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	httpDone := make(chan error, 1)
+	go func() {
+		httpDone <- a.server.Shutdown(shutdownCtx)
+	}()
+
+	drained := true
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		logger.Error("workers did not stop before the shutdown deadline")
+		runErr = errors.Join(runErr, shutdownCtx.Err())
+		drained = false
+	}
+	if err := <-httpDone; err != nil {
+		logger.Error("HTTP server did not drain: %v", err)
+		a.server.Close()
+		runErr = errors.Join(runErr, err)
+		drained = false
+	}
+
+	// Closing the pool waits for every connection to be released, which would
+	// hang on the requests or workers that outlived the deadline
+	if drained {
+		database.ClosePool(a.cfg.Pool)
+	} else if a.cfg.Pool != nil {
+		logger.Error("leaving the database pool open, it is still in use")
+	}
+	logger.Info("✅ Shutdown complete")
+	return runErr
+}
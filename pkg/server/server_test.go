@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nhstop/go-utils/pkg/health"
+)
+
+func newTestApp(shutdownTimeout time.Duration) *App {
+	gin.SetMode(gin.TestMode)
+	return New(gin.New(), &Config{Addr: "127.0.0.1:0", ShutdownTimeout: shutdownTimeout})
+}
+
+func TestRunContextStopsWorkers(t *testing.T) {
+	app := newTestApp(time.Second)
+	stopped := make(chan struct{})
+	app.AddWorker("poller", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := app.RunContext(ctx); err != nil {
+		t.Fatalf("RunContext = %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("worker was not stopped")
+	}
+}
+
+func TestRunContextBoundsStuckWorkers(t *testing.T) {
+	app := newTestApp(100 * time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+	app.AddWorker("stuck", func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := make(chan error, 1)
+	go func() { result <- app.RunContext(ctx) }()
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("RunContext = %v, want the shutdown deadline", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown hung on a stuck worker")
+	}
+}
+
+func TestRunContextShutsDownOnWorkerError(t *testing.T) {
+	app := newTestApp(time.Second)
+	boom := errors.New("boom")
+	app.AddWorker("failing", func(ctx context.Context) error { return boom })
+
+	if err := app.RunContext(context.Background()); !errors.Is(err, boom) {
+		t.Fatalf("RunContext = %v, want %v", err, boom)
+	}
+}
+
+func TestRunContextStopsWorkersBeforeDraining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := health.New()
+	app := New(gin.New(), &Config{
+		Addr:            "127.0.0.1:0",
+		ShutdownTimeout: time.Second,
+		ReadinessDelay:  300 * time.Millisecond,
+		Health:          h,
+	})
+
+	var polls atomic.Int64
+	stoppedAt := make(chan time.Time, 1)
+	app.AddWorker("poller", func(ctx context.Context) error {
+		for ctx.Err() == nil {
+			polls.Add(1)
+			time.Sleep(5 * time.Millisecond)
+		}
+		stoppedAt <- time.Now()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	go app.RunContext(ctx)
+
+	select {
+	case at := <-stoppedAt:
+		// The worker must stop at the start of shutdown, not after ReadinessDelay
+		if elapsed := at.Sub(start); elapsed >= 300*time.Millisecond {
+			t.Fatalf("worker stopped after %v, only once ReadinessDelay had passed", elapsed)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("worker was not stopped")
+	}
+	after := polls.Load()
+	time.Sleep(50 * time.Millisecond)
+	if polls.Load() != after {
+		t.Fatal("worker kept polling after shutdown began")
+	}
+}
+
+func TestRunContextDrainsWorkersAndHTTPInParallel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	release := make(chan struct{})
+	engine.GET("/slow", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	app := New(engine, &Config{Addr: addr, ShutdownTimeout: time.Second})
+
+	app.AddWorker("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(700 * time.Millisecond)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- app.RunContext(ctx) }()
+
+	// Wait for the listener, closing the probe so Shutdown doesn't wait on it
+	var conn net.Conn
+	for range 50 {
+		if conn, err = net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	go http.Get("http://" + addr + "/slow")
+	time.Sleep(50 * time.Millisecond)
+
+	// The request takes 300ms to finish, the worker 700ms to stop: in sequence
+	// they would overrun the 1s deadline
+	cancel()
+	time.AfterFunc(300*time.Millisecond, func() { close(release) })
+	if err := <-result; err != nil {
+		t.Fatalf("RunContext = %v, want a clean shutdown", err)
+	}
+}
@@ -0,0 +1,96 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/nhstop/go-utils/pkg/encryption"
+)
+
+// maxCookieSize leaves room for the cookie name and attributes within the
+// 4096 bytes browsers guarantee per cookie
+const maxCookieSize = 3800
+
+// CookieStore keeps the whole session in the cookie, encrypted with AES-GCM
+type CookieStore struct {
+	keys [][]byte
+}
+
+// cookiePayload is the JSON encrypted into the cookie
+type cookiePayload struct {
+	ID        string         `json:"id"`
+	Values    map[string]any `json:"v"`
+	CreatedAt time.Time      `json:"c"`
+	ExpiresAt time.Time      `json:"e"`
+}
+
+// NewCookieStore creates a CookieStore. Keys must be 16, 24 or 32 bytes; the
+// first encrypts, and every key is tried when decrypting, so a new key can be
+// put first while cookies sealed with the old one stay valid.
+func NewCookieStore(keys ...[]byte) *CookieStore {
+	if len(keys) == 0 {
+		panic("session: NewCookieStore needs at least one key")
+	}
+	for _, k := range keys {
+		if n := len(k); n != 16 && n != 24 && n != 32 {
+			panic("session: cookie keys must be 16, 24 or 32 bytes")
+		}
+	}
+	return &CookieStore{keys: keys}
+}
+
+// Load decrypts a cookie value
+func (s *CookieStore) Load(_ context.Context, cookieValue string) (*Session, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cookieValue)
+	if err != nil || len(data) == 0 {
+		return nil, ErrNotFound
+	}
+
+	for _, key := range s.keys {
+		plaintext, err := encryption.Decrypt(data, key)
+		if err != nil {
+			continue
+		}
+		var p cookiePayload
+		if err := json.Unmarshal([]byte(plaintext), &p); err != nil {
+			return nil, ErrNotFound
+		}
+		if time.Now().After(p.ExpiresAt) {
+			return nil, ErrNotFound
+		}
+		if p.Values == nil {
+			p.Values = make(map[string]any)
+		}
+		return &Session{ID: p.ID, Values: p.Values, CreatedAt: p.CreatedAt, ExpiresAt: p.ExpiresAt}, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Save encrypts the session with the first key
+func (s *CookieStore) Save(_ context.Context, sess *Session) (string, error) {
+	plaintext, err := json.Marshal(cookiePayload{
+		ID:        sess.ID,
+		Values:    sess.Values,
+		CreatedAt: sess.CreatedAt,
+		ExpiresAt: sess.ExpiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	data, err := encryption.Encrypt(string(plaintext), s.keys[0])
+	if err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(data)
+	if len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return value, nil
+}
+
+// Delete is a no-op, expiring the cookie is enough
+func (s *CookieStore) Delete(context.Context, *Session) error {
+	return nil
+}
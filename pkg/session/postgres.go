@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore keeps sessions in a Postgres table, the cookie only holds the ID
+type PostgresStore struct {
+	pool  *pgxpool.Pool
+	table string
+}
+
+// NewPostgresStore creates a PostgresStore using table (defaults to "sessions")
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	if table == "" {
+		table = "sessions"
+	}
+	return &PostgresStore{
+		pool:  pool,
+		table: pgx.Identifier{table}.Sanitize(),
+	}
+}
+
+// CreateTable creates the sessions table if it does not exist
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id         TEXT PRIMARY KEY,
+			data       JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		)`, s.table))
+	return err
+}
+
+// Load reads an unexpired session by ID
+func (s *PostgresStore) Load(ctx context.Context, id string) (*Session, error) {
+	sess := &Session{ID: id}
+	var data []byte
+	err := s.pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT data, created_at, expires_at FROM %s
+		WHERE id = $1 AND expires_at > now()`, s.table), id).
+		Scan(&data, &sess.CreatedAt, &sess.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &sess.Values); err != nil {
+		return nil, err
+	}
+	if sess.Values == nil {
+		sess.Values = make(map[string]any)
+	}
+	return sess, nil
+}
+
+// Save upserts the session and drops the ID it replaced after Regenerate
+func (s *PostgresStore) Save(ctx context.Context, sess *Session) (string, error) {
+	data, err := json.Marshal(sess.Values)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if sess.PreviousID() != "" {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, s.table), sess.PreviousID()); err != nil {
+			return "", err
+		}
+	}
+	_, err = tx.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s (id, data, created_at, expires_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, expires_at = EXCLUDED.expires_at`, s.table),
+		sess.ID, data, sess.CreatedAt, sess.ExpiresAt)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return sess.ID, nil
+}
+
+// Delete removes the session and any ID it replaced
+func (s *PostgresStore) Delete(ctx context.Context, sess *Session) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, s.table),
+		[]string{sess.ID, sess.PreviousID()})
+	return err
+}
+
+// DeleteExpired removes expired sessions
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE expires_at < now()`, s.table))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
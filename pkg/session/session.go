@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+// Session holds per-client values. Values round-trip through JSON, so numbers
+// come back as float64.
+type Session struct {
+	ID        string
+	Values    map[string]any
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	isNew      bool
+	modified   bool
+	destroyed  bool
+	previousID string // set by Regenerate so stores can drop the old ID
+}
+
+// Store loads and saves sessions. The cookie holds whatever Save returns:
+// the encrypted session for CookieStore, or only its ID for server-side stores.
+type Store interface {
+	// Load returns ErrNotFound when the cookie value is unknown, invalid or expired
+	Load(ctx context.Context, cookieValue string) (*Session, error)
+	Save(ctx context.Context, s *Session) (cookieValue string, err error)
+	Delete(ctx context.Context, s *Session) error
+}
+
+var (
+	ErrNotFound       = errors.New("session: not found")
+	ErrCookieTooLarge = errors.New("session: encoded session exceeds the cookie size limit")
+)
+
+// New creates an empty, unsaved session
+func New(maxAge time.Duration) *Session {
+	now := time.Now().UTC()
+	return &Session{
+		ID:        newID(),
+		Values:    make(map[string]any),
+		CreatedAt: now,
+		ExpiresAt: now.Add(maxAge),
+		isNew:     true,
+	}
+}
+
+// Get returns the value for key, or nil
+func (s *Session) Get(key string) any {
+	return s.Values[key]
+}
+
+// GetString returns the value for key if it is a string
+func (s *Session) GetString(key string) string {
+	v, _ := s.Values[key].(string)
+	return v
+}
+
+// Set stores a JSON-serializable value
+func (s *Session) Set(key string, value any) {
+	s.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes key
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.modified = true
+}
+
+// Regenerate gives the session a new ID while keeping its values.
+// Call it on login and privilege changes to prevent session fixation.
+func (s *Session) Regenerate() {
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.ID
+	}
+	s.ID = newID()
+	s.CreatedAt = time.Now().UTC()
+	s.modified = true
+}
+
+// Destroy clears the session and expires its cookie, e.g. on logout
+func (s *Session) Destroy() {
+	s.Values = make(map[string]any)
+	s.destroyed = true
+}
+
+// IsNew reports whether the session was created for this request
+func (s *Session) IsNew() bool { return s.isNew }
+
+// Modified reports whether values changed or the ID was regenerated
+func (s *Session) Modified() bool { return s.modified }
+
+// Destroyed reports whether Destroy was called
+func (s *Session) Destroyed() bool { return s.destroyed }
+
+// PreviousID is the ID replaced by Regenerate, empty otherwise
+func (s *Session) PreviousID() string { return s.previousID }
+
+// newID returns 256 random bits, base64url encoded
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("session: failed to generate ID: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
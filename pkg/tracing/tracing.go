@@ -0,0 +1,35 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies spans created by this module
+const InstrumentationName = "github.com/nhstop/go-utils"
+
+var defaultPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Propagator returns the global propagator, falling back to W3C Trace Context
+// and Baggage when the application has not called otel.SetTextMapPropagator
+func Propagator() propagation.TextMapPropagator {
+	p := otel.GetTextMapPropagator()
+	if len(p.Fields()) == 0 {
+		return defaultPropagator
+	}
+	return p
+}
+
+// Tracer returns a tracer from provider, or from the global provider when nil.
+// Without a configured SDK the global provider is a no-op, so instrumentation
+// costs next to nothing until otel.SetTracerProvider is called.
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(InstrumentationName)
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore records delivery attempts in a Postgres table
+type PostgresStore struct {
+	pool  *pgxpool.Pool
+	table string
+	index string
+}
+
+// NewPostgresStore creates a PostgresStore using table (defaults to "webhook_deliveries")
+func NewPostgresStore(pool *pgxpool.Pool, table string) *PostgresStore {
+	if table == "" {
+		table = "webhook_deliveries"
+	}
+	return &PostgresStore{
+		pool:  pool,
+		table: pgx.Identifier{table}.Sanitize(),
+		index: pgx.Identifier{table + "_delivery_id_idx"}.Sanitize(),
+	}
+}
+
+// CreateTable creates the attempts table if it does not exist
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			id           BIGSERIAL PRIMARY KEY,
+			delivery_id  TEXT NOT NULL,
+			url          TEXT NOT NULL,
+			event        TEXT NOT NULL DEFAULT '',
+			attempt      INTEGER NOT NULL,
+			status_code  INTEGER,
+			error        TEXT NOT NULL DEFAULT '',
+			duration_ms  BIGINT NOT NULL,
+			attempted_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS %[2]s ON %[1]s (delivery_id)`,
+		s.table, s.index))
+	return err
+}
+
+// RecordAttempt implements AttemptStore
+func (s *PostgresStore) RecordAttempt(ctx context.Context, a *Attempt) error {
+	var status *int
+	if a.StatusCode != 0 {
+		status = &a.StatusCode
+	}
+	_, err := s.pool.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO %s (delivery_id, url, event, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, s.table),
+		a.DeliveryID, a.URL, a.Event, a.Number, status, a.Error, a.Duration.Milliseconds(), a.AttemptedAt)
+	return err
+}
+
+// Attempts returns every attempt for a delivery, oldest first
+func (s *PostgresStore) Attempts(ctx context.Context, deliveryID string) ([]*Attempt, error) {
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT delivery_id, url, event, attempt, COALESCE(status_code, 0), error, duration_ms, attempted_at
+		FROM %s WHERE delivery_id = $1 ORDER BY attempted_at, id`, s.table), deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*Attempt
+	for rows.Next() {
+		a := &Attempt{}
+		var durationMs int64
+		if err := rows.Scan(&a.DeliveryID, &a.URL, &a.Event, &a.Number, &a.StatusCode,
+			&a.Error, &durationMs, &a.AttemptedAt); err != nil {
+			return nil, err
+		}
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// DeleteBefore removes attempts made before t
+func (s *PostgresStore) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE attempted_at < $1`, s.table), t)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestPostgresStoreRecordsAttempts(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	store := NewPostgresStore(pool, "webhook_deliveries_test")
+	if _, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+store.table); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+store.table)
+
+	srv, _ := statusServer(t, http.StatusBadGateway, http.StatusOK)
+	s := newTestSender(store)
+	d := &Delivery{URL: srv.URL, Event: "order.paid", Payload: []byte("{}")}
+	if err := s.Send(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	attempts, err := store.Attempts(ctx, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("%d attempts recorded, want 2", len(attempts))
+	}
+	if a := attempts[0]; a.Number != 1 || a.StatusCode != http.StatusBadGateway || a.Error == "" || a.Event != "order.paid" {
+		t.Fatalf("first attempt = %+v", a)
+	}
+	if a := attempts[1]; a.Number != 2 || a.StatusCode != http.StatusOK || a.Error != "" {
+		t.Fatalf("second attempt = %+v", a)
+	}
+
+	if n, err := store.DeleteBefore(ctx, time.Now().Add(time.Minute)); err != nil || n != 2 {
+		t.Fatalf("DeleteBefore = %d, %v, want 2", n, err)
+	}
+}
@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nhstop/go-utils/pkg/logger"
+	"github.com/nhstop/go-utils/pkg/queue"
+)
+
+// Headers sent with every delivery besides the scheme's own. Receivers can
+// use the ID to drop duplicates, retries reuse it.
+const (
+	HeaderID    = "Webhook-Id"
+	HeaderEvent = "Webhook-Event"
+)
+
+// EnvelopeType is the queue.MessageEnvelope type used by Enqueue
+const EnvelopeType = "webhook"
+
+// Delivery is a payload to POST to an endpoint
+type Delivery struct {
+	ID      string          `json:"id"` // generated when empty
+	URL     string          `json:"url"`
+	Event   string          `json:"event,omitempty"` // e.g. "order.paid"
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Attempt is the outcome of one delivery attempt
+type Attempt struct {
+	DeliveryID  string
+	URL         string
+	Event       string
+	Number      int // 1 for the first attempt
+	StatusCode  int // 0 when no response was received
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+// AttemptStore records delivery attempts
+type AttemptStore interface {
+	RecordAttempt(ctx context.Context, a *Attempt) error
+}
+
+// ErrRejected is returned when the endpoint answers with a 4xx other than 408
+// or 429, which retrying won't fix
+var ErrRejected = errors.New("webhook: endpoint rejected the delivery")
+
+// SenderConfig configures a Sender
+type SenderConfig struct {
+	Scheme Scheme // defaults to DefaultScheme(), matching VerifyWebhook
+	Secret string // signs every delivery, unless SecretFunc is set
+	// SecretFunc looks up the secret per delivery, e.g. per customer endpoint
+	SecretFunc func(ctx context.Context, d *Delivery) (string, error)
+	// Client defaults to a client with a 10s timeout. Redirects aren't
+	// followed, as they'd turn the POST into a GET, unless Client sets its
+	// own CheckRedirect.
+	Client    *http.Client
+	UserAgent string // defaults to "go-utils-webhook"
+
+	MaxAttempts int           // defaults to 5
+	BaseDelay   time.Duration // delay after the first failure, doubled after each, defaults to 1s
+	MaxDelay    time.Duration // cap on a single delay, defaults to 1 minute
+
+	Store AttemptStore // optional, e.g. NewPostgresStore
+}
+
+// DefaultSenderConfig retries up to 5 times, waiting about 1s, 2s, 4s and 8s
+func DefaultSenderConfig() *SenderConfig {
+	return &SenderConfig{
+		Scheme:      DefaultScheme(),
+		Client:      &http.Client{Timeout: 10 * time.Second, CheckRedirect: noRedirects},
+		UserAgent:   "go-utils-webhook",
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+}
+
+// Sender signs and delivers webhooks
+type Sender struct {
+	cfg *SenderConfig
+}
+
+// NewSender creates a Sender, Secret or SecretFunc is required
+func NewSender(cfg *SenderConfig) *Sender {
+	if cfg == nil || (cfg.Secret == "" && cfg.SecretFunc == nil) {
+		panic("webhook: SenderConfig.Secret or SecretFunc is required")
+	}
+	defaults := DefaultSenderConfig()
+	if cfg.Scheme.SignatureHeader == "" {
+		cfg.Scheme = defaults.Scheme
+	}
+	if cfg.Client == nil {
+		cfg.Client = defaults.Client
+	} else if cfg.Client.CheckRedirect == nil {
+		client := *cfg.Client
+		client.CheckRedirect = noRedirects
+		cfg.Client = &client
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaults.UserAgent
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaults.MaxAttempts
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = defaults.BaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = defaults.MaxDelay
+	}
+	return &Sender{cfg: cfg}
+}
+
+// Send delivers d, retrying network errors, 408, 429 and 5xx responses with
+// exponential backoff and jitter. Every attempt is recorded in the Store.
+// Rejections return ErrRejected without retrying.
+func (s *Sender) Send(ctx context.Context, d *Delivery) error {
+	if d.ID == "" {
+		d.ID = newDeliveryID()
+	}
+	secret := s.cfg.Secret
+	if s.cfg.SecretFunc != nil {
+		var err error
+		if secret, err = s.cfg.SecretFunc(ctx, d); err != nil {
+			return fmt.Errorf("webhook: failed to get secret for delivery %s: %w", d.ID, err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= s.cfg.MaxAttempts; attempt++ {
+		retryAfter, err := s.attempt(ctx, d, secret, attempt)
+		if err == nil || errors.Is(err, ErrRejected) {
+			return err
+		}
+		lastErr = err
+		if attempt == s.cfg.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(max(s.backoff(attempt), min(retryAfter, s.cfg.MaxDelay))):
+		}
+	}
+	return fmt.Errorf("webhook: delivery %s failed after %d attempts: %w", d.ID, s.cfg.MaxAttempts, lastErr)
+}
+
+// attempt makes one request, returning the delay asked for in Retry-After if any
+func (s *Sender) attempt(ctx context.Context, d *Delivery, secret string, number int) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.cfg.UserAgent)
+	req.Header.Set(HeaderID, d.ID)
+	if d.Event != "" {
+		req.Header.Set(HeaderEvent, d.Event)
+	}
+	s.cfg.Scheme.SignRequest(req, d.Payload, secret, time.Now())
+
+	rec := &Attempt{DeliveryID: d.ID, URL: d.URL, Event: d.Event, Number: number, AttemptedAt: time.Now()}
+	resp, err := s.cfg.Client.Do(req)
+	rec.Duration = time.Since(rec.AttemptedAt)
+
+	var retryAfter time.Duration
+	if err == nil {
+		// Drain so the connection can be reused
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		rec.StatusCode = resp.StatusCode
+
+		switch code := resp.StatusCode; {
+		case code >= 200 && code < 300:
+		case code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500:
+			err = fmt.Errorf("webhook: unexpected status %d", code)
+			if secs, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && secs > 0 {
+				retryAfter = time.Duration(secs) * time.Second
+			}
+		default:
+			err = fmt.Errorf("%w with status %d", ErrRejected, code)
+		}
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	if s.cfg.Store != nil {
+		// Recorded even if ctx was cancelled mid-request
+		if serr := s.cfg.Store.RecordAttempt(context.WithoutCancel(ctx), rec); serr != nil {
+			logger.Error("failed to record webhook attempt for %s: %v", d.ID, serr)
+		}
+	}
+	return retryAfter, err
+}
+
+// noRedirects returns a 3xx as the response, which attempt treats as a rejection
+func noRedirects(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// backoff doubles BaseDelay per attempt up to MaxDelay, then picks a random
+// delay in its upper half so failing senders don't retry in lockstep
+func (s *Sender) backoff(attempt int) time.Duration {
+	d := s.cfg.BaseDelay
+	for i := 1; i < attempt && d < s.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	half := min(d, s.cfg.MaxDelay) / 2
+	return half + mathrand.N(half+1)
+}
+
+// ---- Queue ----
+
+// Enqueue sends d through an SQS queue, so it survives restarts and is
+// delivered by a worker running HandleMessage
+func (s *Sender) Enqueue(ctx context.Context, client *sqs.Client, queueURL string, d *Delivery) error {
+	if d.ID == "" {
+		d.ID = newDeliveryID()
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(queue.MessageEnvelope{Type: EnvelopeType, Data: data})
+	if err != nil {
+		return err
+	}
+	return queue.Send(ctx, client, queueURL, string(body), nil)
+}
+
+// HandleMessage is a queue.MessageHandler delivering enqueued webhooks, e.g.
+// server.QueueWorker(client, url, cfg, sender.HandleMessage). Rejected
+// deliveries are dropped, others are left on the queue to be retried once
+// every attempt failed, so keep the queue's VisibilityTimeout above the
+// total backoff.
+func (s *Sender) HandleMessage(ctx context.Context, msg types.Message) error {
+	var env queue.MessageEnvelope
+	if msg.Body == nil {
+		return errors.New("webhook: empty message")
+	}
+	if err := json.Unmarshal([]byte(*msg.Body), &env); err != nil {
+		return err
+	}
+	if env.Type != EnvelopeType {
+		return fmt.Errorf("webhook: unexpected message type %q", env.Type)
+	}
+	return s.HandleEnvelope(ctx, env.Data)
+}
+
+// HandleEnvelope delivers the data of a "webhook" envelope, for handlers
+// that dispatch several message types from one queue
+func (s *Sender) HandleEnvelope(ctx context.Context, data json.RawMessage) error {
+	var d Delivery
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	err := s.Send(ctx, &d)
+	if errors.Is(err, ErrRejected) {
+		logger.Error("dropping webhook %s to %s: %v", d.ID, d.URL, err)
+		return nil
+	}
+	return err
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
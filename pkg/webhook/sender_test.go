@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nhstop/go-utils/pkg/queue"
+)
+
+// memoryStore keeps attempts in memory
+type memoryStore struct {
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+func (m *memoryStore) RecordAttempt(_ context.Context, a *Attempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts = append(m.attempts, *a)
+	return nil
+}
+
+func (m *memoryStore) statuses() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	codes := make([]int, len(m.attempts))
+	for i, a := range m.attempts {
+		codes[i] = a.StatusCode
+	}
+	return codes
+}
+
+// statusServer answers with each status in turn, repeating the last one
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func newTestSender(store AttemptStore) *Sender {
+	return NewSender(&SenderConfig{
+		Secret:      "secret",
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		Store:       store,
+	})
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	payload := []byte(`{"order":42}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get(HeaderEvent) != "order.paid" || r.Header.Get(HeaderID) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := DefaultScheme().Verify(r.Header, body, []string{"secret"}, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &Delivery{URL: srv.URL, Event: "order.paid", Payload: payload}
+	if err := newTestSender(nil).Send(context.Background(), d); err != nil {
+		t.Fatalf("Send = %v", err)
+	}
+	if d.ID == "" {
+		t.Fatal("delivery ID was not generated")
+	}
+}
+
+func TestSendRetriesTransientFailures(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusTooManyRequests, http.StatusRequestTimeout} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			srv, calls := statusServer(t, status, status, http.StatusOK)
+			store := &memoryStore{}
+			if err := newTestSender(store).Send(context.Background(), &Delivery{URL: srv.URL, Payload: []byte("{}")}); err != nil {
+				t.Fatalf("Send = %v", err)
+			}
+			if calls.Load() != 3 {
+				t.Fatalf("%d calls, want 3", calls.Load())
+			}
+			if got := store.statuses(); len(got) != 3 || got[0] != status || got[2] != http.StatusOK {
+				t.Fatalf("recorded statuses = %v", got)
+			}
+		})
+	}
+}
+
+func TestSendGivesUpAfterMaxAttempts(t *testing.T) {
+	srv, calls := statusServer(t, http.StatusServiceUnavailable)
+	err := newTestSender(nil).Send(context.Background(), &Delivery{URL: srv.URL, Payload: []byte("{}")})
+	if err == nil || errors.Is(err, ErrRejected) {
+		t.Fatalf("Send = %v, want a retryable error", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("%d calls, want 3", calls.Load())
+	}
+}
+
+func TestSendDoesNotRetryRejections(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusGone} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			srv, calls := statusServer(t, status)
+			err := newTestSender(nil).Send(context.Background(), &Delivery{URL: srv.URL, Payload: []byte("{}")})
+			if !errors.Is(err, ErrRejected) {
+				t.Fatalf("Send = %v, want ErrRejected", err)
+			}
+			if calls.Load() != 1 {
+				t.Fatalf("%d calls, want 1", calls.Load())
+			}
+		})
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	var followed atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusFound)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		followed.Store(true)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for name, client := range map[string]*http.Client{"default client": nil, "custom client": {Timeout: time.Second}} {
+		t.Run(name, func(t *testing.T) {
+			s := NewSender(&SenderConfig{Secret: "secret", Client: client})
+			err := s.Send(context.Background(), &Delivery{URL: srv.URL + "/hook", Payload: []byte("{}")})
+			if !errors.Is(err, ErrRejected) {
+				t.Fatalf("Send = %v, want ErrRejected", err)
+			}
+			if followed.Load() {
+				t.Fatal("redirect was followed")
+			}
+		})
+	}
+}
+
+func TestSendCapsRetryAfter(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- newTestSender(nil).Send(context.Background(), &Delivery{URL: srv.URL, Payload: []byte("{}")})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Send = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Retry-After was not capped at MaxDelay")
+	}
+}
+
+func TestSendStopsWaitingWhenCancelled(t *testing.T) {
+	srv, calls := statusServer(t, http.StatusInternalServerError)
+	s := NewSender(&SenderConfig{Secret: "secret", BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	done := make(chan error, 1)
+	go func() { done <- s.Send(ctx, &Delivery{URL: srv.URL, Payload: []byte("{}")}) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Send = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Send kept waiting after ctx was cancelled")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("%d calls, want 1", calls.Load())
+	}
+}
+
+func TestBackoffStaysWithinBounds(t *testing.T) {
+	s := NewSender(&SenderConfig{Secret: "secret", BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{30, time.Second},
+	}
+	for _, tt := range tests {
+		for range 200 {
+			if d := s.backoff(tt.attempt); d < tt.max/2 || d > tt.max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, d, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+func envelopeMessage(t *testing.T, d *Delivery) types.Message {
+	t.Helper()
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(queue.MessageEnvelope{Type: EnvelopeType, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Message{Body: aws.String(string(body))}
+}
+
+func TestHandleMessage(t *testing.T) {
+	s := newTestSender(nil)
+	ctx := context.Background()
+
+	rejected, _ := statusServer(t, http.StatusBadRequest)
+	if err := s.HandleMessage(ctx, envelopeMessage(t, &Delivery{URL: rejected.URL, Payload: []byte("{}")})); err != nil {
+		t.Fatalf("HandleMessage = %v, want rejected deliveries dropped", err)
+	}
+
+	failing, _ := statusServer(t, http.StatusServiceUnavailable)
+	if err := s.HandleMessage(ctx, envelopeMessage(t, &Delivery{URL: failing.URL, Payload: []byte("{}")})); err == nil {
+		t.Fatal("HandleMessage = nil, want an error so the message is retried")
+	}
+
+	if err := s.HandleMessage(ctx, types.Message{Body: aws.String(`{"type":"email","data":{}}`)}); err == nil {
+		t.Fatal("HandleMessage accepted another message type")
+	}
+	if err := s.HandleMessage(ctx, types.Message{}); err == nil {
+		t.Fatal("HandleMessage accepted an empty message")
+	}
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoding is how signatures are written in the signature header
+type Encoding int
+
+const (
+	Hex Encoding = iota
+	Base64
+)
+
+// Scheme describes how a provider signs webhooks: an HMAC-SHA256 over the raw
+// body, prefixed with the timestamp and a "." when TimestampHeader is set
+type Scheme struct {
+	SignatureHeader string // e.g. "X-Hub-Signature-256"
+	Prefix          string // before the signature, e.g. "sha256="
+	Encoding        Encoding
+	// TimestampHeader carries Unix seconds, signed along with the body so old
+	// deliveries can't be replayed. Empty for providers that don't send one.
+	TimestampHeader string
+	Tolerance       time.Duration // maximum age of the timestamp, defaults to 5 minutes
+}
+
+// DefaultScheme is what our own webhooks use
+func DefaultScheme() Scheme {
+	return Scheme{
+		SignatureHeader: "Webhook-Signature",
+		Prefix:          "sha256=",
+		Encoding:        Hex,
+		TimestampHeader: "Webhook-Timestamp",
+		Tolerance:       5 * time.Minute,
+	}
+}
+
+// GitHubScheme verifies GitHub's X-Hub-Signature-256, which has no timestamp
+func GitHubScheme() Scheme {
+	return Scheme{
+		SignatureHeader: "X-Hub-Signature-256",
+		Prefix:          "sha256=",
+		Encoding:        Hex,
+	}
+}
+
+var (
+	ErrMissingSignature = errors.New("webhook: missing signature")
+	ErrInvalidSignature = errors.New("webhook: signature does not match")
+	ErrInvalidTimestamp = errors.New("webhook: missing or invalid timestamp")
+	ErrTimestampExpired = errors.New("webhook: timestamp outside the tolerance window")
+)
+
+// Sign returns the signature header value for body signed at ts
+func (s Scheme) Sign(body []byte, secret string, ts time.Time) string {
+	return s.Prefix + s.signature(s.payload(body, strconv.FormatInt(ts.Unix(), 10)), secret)
+}
+
+// SignRequest sets the signature and, if the scheme uses one, timestamp headers
+func (s Scheme) SignRequest(req *http.Request, body []byte, secret string, now time.Time) {
+	if s.TimestampHeader != "" {
+		req.Header.Set(s.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	}
+	req.Header.Set(s.SignatureHeader, s.Sign(body, secret, now))
+}
+
+// Verify checks the signature in header against each secret, so several can
+// be active while one is rotated out. The header may list several signatures
+// separated by commas or spaces. Empty secrets never match.
+func (s Scheme) Verify(header http.Header, body []byte, secrets []string, now time.Time) error {
+	sent := header.Get(s.SignatureHeader)
+	if sent == "" {
+		return ErrMissingSignature
+	}
+
+	timestamp := ""
+	if s.TimestampHeader != "" {
+		timestamp = header.Get(s.TimestampHeader)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrInvalidTimestamp
+		}
+		tolerance := s.Tolerance
+		if tolerance <= 0 {
+			tolerance = 5 * time.Minute
+		}
+		if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	// Drop candidates without a signature after the prefix before comparing
+	var candidates [][]byte
+	for _, c := range strings.FieldsFunc(sent, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if sig, ok := strings.CutPrefix(c, s.Prefix); ok && sig != "" {
+			candidates = append(candidates, []byte(c))
+		}
+	}
+	if len(candidates) == 0 {
+		return ErrInvalidSignature
+	}
+
+	payload := s.payload(body, timestamp)
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		expected := []byte(s.Prefix + s.signature(payload, secret))
+		for _, candidate := range candidates {
+			if hmac.Equal(candidate, expected) {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func (s Scheme) payload(body []byte, timestamp string) []byte {
+	if s.TimestampHeader == "" {
+		return body
+	}
+	return append([]byte(timestamp+"."), body...)
+}
+
+// signature is the HMAC-SHA256 of payload in the scheme's encoding
+func (s Scheme) signature(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	sum := mac.Sum(nil)
+	if s.Encoding == Base64 {
+		return base64.StdEncoding.EncodeToString(sum)
+	}
+	return hex.EncodeToString(sum)
+}
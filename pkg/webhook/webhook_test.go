@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// GitHub's documented example: secret "It's a Secret to Everybody", body "Hello, World!"
+const gitHubExample = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+func TestGitHubSchemeMatchesDocumentedExample(t *testing.T) {
+	s := GitHubScheme()
+	if got := s.Sign([]byte("Hello, World!"), "It's a Secret to Everybody", time.Time{}); got != gitHubExample {
+		t.Fatalf("Sign = %q, want %q", got, gitHubExample)
+	}
+
+	h := http.Header{}
+	h.Set(s.SignatureHeader, gitHubExample)
+	if err := s.Verify(h, []byte("Hello, World!"), []string{"It's a Secret to Everybody"}, time.Now()); err != nil {
+		t.Fatalf("Verify = %v", err)
+	}
+}
+
+func TestVerifyRejectsForgedSignatures(t *testing.T) {
+	s := GitHubScheme()
+	secrets := []string{"secret"}
+	tests := []struct {
+		name string
+		sig  string
+		body string
+	}{
+		{"prefix only with empty body", "sha256=", ""},
+		{"prefix only", "sha256=", "{}"},
+		{"separators only", " , ", "{}"},
+		{"missing prefix", s.Sign([]byte("{}"), "secret", time.Time{})[len("sha256="):], "{}"},
+		{"wrong secret", s.Sign([]byte("{}"), "other", time.Time{}), "{}"},
+		{"tampered body", s.Sign([]byte("{}"), "secret", time.Time{}), `{"admin":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			h.Set(s.SignatureHeader, tt.sig)
+			if err := s.Verify(h, []byte(tt.body), secrets, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Verify = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIgnoresEmptySecrets(t *testing.T) {
+	s := GitHubScheme()
+	h := http.Header{}
+	h.Set(s.SignatureHeader, s.Sign([]byte("{}"), "", time.Time{}))
+	if err := s.Verify(h, []byte("{}"), []string{""}, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyRotatedSecretsAndMultipleSignatures(t *testing.T) {
+	s := DefaultScheme()
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"order.paid"}`)
+
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	s.SignRequest(req, body, "new", now)
+	if err := s.Verify(req.Header, body, []string{"old", "new"}, now); err != nil {
+		t.Fatalf("Verify with rotated secret = %v", err)
+	}
+
+	req.Header.Set(s.SignatureHeader, "sha256=deadbeef, "+s.Sign(body, "old", now))
+	if err := s.Verify(req.Header, body, []string{"old"}, now); err != nil {
+		t.Fatalf("Verify with several signatures = %v", err)
+	}
+}
+
+func TestVerifyTimestamp(t *testing.T) {
+	s := DefaultScheme()
+	signedAt := time.Unix(1700000000, 0)
+	body := []byte("{}")
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	s.SignRequest(req, body, "secret", signedAt)
+
+	if err := s.Verify(req.Header, body, []string{"secret"}, signedAt.Add(6*time.Minute)); !errors.Is(err, ErrTimestampExpired) {
+		t.Fatalf("Verify of an old delivery = %v, want ErrTimestampExpired", err)
+	}
+
+	// The timestamp is signed, so moving it forward breaks the signature
+	req.Header.Set(s.TimestampHeader, "1700000300")
+	if err := s.Verify(req.Header, body, []string{"secret"}, signedAt.Add(6*time.Minute)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify with a replaced timestamp = %v, want ErrInvalidSignature", err)
+	}
+
+	req.Header.Del(s.TimestampHeader)
+	if err := s.Verify(req.Header, body, []string{"secret"}, signedAt); !errors.Is(err, ErrInvalidTimestamp) {
+		t.Fatalf("Verify without a timestamp = %v, want ErrInvalidTimestamp", err)
+	}
+
+	req.Header.Del(s.SignatureHeader)
+	if err := s.Verify(req.Header, body, []string{"secret"}, signedAt); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("Verify without a signature = %v, want ErrMissingSignature", err)
+	}
+}
+
+func TestBase64Encoding(t *testing.T) {
+	s := Scheme{SignatureHeader: "X-Signature", Encoding: Base64}
+	sig := s.Sign([]byte("Hello, World!"), "It's a Secret to Everybody", time.Time{})
+	if want := "dXEH6g6yUJ/CESIczphLijdXC211hsIsRvQ3nIsEPhc="; sig != want {
+		t.Fatalf("Sign = %q, want %q", sig, want)
+	}
+}